@@ -0,0 +1,156 @@
+package writer
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// LevelHandler 运行时查看和修改日志级别的 HTTP 接口
+//
+//	GET ?writer=es                                   返回级别状态，省略 writer 时返回全部
+//	PUT {"writer":"es","level":"debug","ttl":"15m"}  修改级别，省略 writer 时修改全部，ttl 到期后自动恢复
+type LevelHandler struct {
+	mu     sync.RWMutex
+	levels map[string]*AtomicLevel
+	maxTTL time.Duration
+}
+
+// LevelState 单个写入器的级别状态
+type LevelState struct {
+	Level    Level      `json:"level"`               // 当前级别
+	Base     Level      `json:"base"`                // 临时级别到期后恢复的级别
+	RevertAt *time.Time `json:"revert_at,omitempty"` // 临时级别的到期时间
+}
+
+// levelRequest PUT 请求体
+type levelRequest struct {
+	Writer string `json:"writer,omitempty"`
+	Level  string `json:"level"`
+	TTL    string `json:"ttl,omitempty"`
+}
+
+// NewLevelHandler 创建级别管理接口，maxTTL > 0 时 PUT 必须携带不超过 maxTTL 的 ttl（避免忘记恢复）
+func NewLevelHandler(maxTTL time.Duration) *LevelHandler {
+	return &LevelHandler{
+		levels: make(map[string]*AtomicLevel),
+		maxTTL: maxTTL,
+	}
+}
+
+// Add 注册一个写入器的级别，同名会被替换
+func (h *LevelHandler) Add(name string, level *AtomicLevel) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.levels[name] = level
+}
+
+// Remove 移除一个写入器的级别
+func (h *LevelHandler) Remove(name string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.levels, name)
+}
+
+// ServeHTTP 实现 http.Handler 接口
+func (h *LevelHandler) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.serveGet(rw, r.URL.Query().Get("writer"))
+	case http.MethodPut:
+		h.servePut(rw, r)
+	default:
+		rw.Header().Set("Allow", "GET, PUT")
+		writeJSONError(rw, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+// serveGet 返回级别状态
+func (h *LevelHandler) serveGet(rw http.ResponseWriter, name string) {
+	levels, err := h.lookup(name)
+	if err != nil {
+		writeJSONError(rw, http.StatusNotFound, err.Error())
+		return
+	}
+	writeJSON(rw, http.StatusOK, levelStates(levels))
+}
+
+// servePut 修改级别
+func (h *LevelHandler) servePut(rw http.ResponseWriter, r *http.Request) {
+	var req levelRequest
+	if err := json.NewDecoder(http.MaxBytesReader(rw, r.Body, 4096)).Decode(&req); err != nil {
+		writeJSONError(rw, http.StatusBadRequest, fmt.Sprintf("invalid request body: %v", err))
+		return
+	}
+	level, err := ParseLevel(req.Level)
+	if err != nil {
+		writeJSONError(rw, http.StatusBadRequest, err.Error())
+		return
+	}
+	var ttl time.Duration
+	if req.TTL != "" {
+		if ttl, err = time.ParseDuration(req.TTL); err != nil || ttl < 0 {
+			writeJSONError(rw, http.StatusBadRequest, fmt.Sprintf("invalid ttl: %q", req.TTL))
+			return
+		}
+	}
+	if h.maxTTL > 0 && (ttl == 0 || ttl > h.maxTTL) {
+		writeJSONError(rw, http.StatusBadRequest, fmt.Sprintf("ttl is required and must not exceed %s", h.maxTTL))
+		return
+	}
+
+	levels, err := h.lookup(req.Writer)
+	if err != nil {
+		writeJSONError(rw, http.StatusNotFound, err.Error())
+		return
+	}
+	for _, l := range levels {
+		l.SetLevelFor(level, ttl)
+	}
+	writeJSON(rw, http.StatusOK, levelStates(levels))
+}
+
+// lookup 返回 name 对应的级别，name 为空时返回全部
+func (h *LevelHandler) lookup(name string) (map[string]*AtomicLevel, error) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	if name == "" {
+		all := make(map[string]*AtomicLevel, len(h.levels))
+		for n, l := range h.levels {
+			all[n] = l
+		}
+		return all, nil
+	}
+	l, ok := h.levels[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown writer: %q", name)
+	}
+	return map[string]*AtomicLevel{name: l}, nil
+}
+
+// levelStates 生成级别状态
+func levelStates(levels map[string]*AtomicLevel) map[string]LevelState {
+	states := make(map[string]LevelState, len(levels))
+	for name, l := range levels {
+		state := LevelState{Level: l.Level(), Base: l.Base()}
+		if at := l.RevertAt(); !at.IsZero() {
+			state.RevertAt = &at
+		}
+		states[name] = state
+	}
+	return states
+}
+
+// writeJSON 输出 JSON 响应
+func writeJSON(rw http.ResponseWriter, status int, v any) {
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(status)
+	json.NewEncoder(rw).Encode(v)
+}
+
+// writeJSONError 输出 JSON 错误响应
+func writeJSONError(rw http.ResponseWriter, status int, msg string) {
+	writeJSON(rw, status, map[string]string{"error": msg})
+}
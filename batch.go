@@ -0,0 +1,802 @@
+package writer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Sink 批量写入的目标后端，由 BatchWriter 负责缓冲、重试、死信和统计。
+//
+// WriteBatch 返回 nil 表示整批写入成功；返回 *BatchError 表示部分条目失败；
+// 返回其他错误表示整批失败，用 Retryable 包装的错误会按 RetryPolicy 重试，未包装的视为永久失败。
+type Sink interface {
+	WriteBatch(ctx context.Context, entries []LogEntry) error
+}
+
+// TargetSink 可报告写入目标（如索引名、表名）的 Sink，用于死信记录
+type TargetSink interface {
+	Target() string
+}
+
+// SinkStats Sink 自行统计的发送字节数
+type SinkStats struct {
+	BytesRaw  uint64 // 压缩前的字节数
+	BytesSent uint64 // 实际发送的字节数
+}
+
+// SinkStatsProvider 可提供发送字节数的 Sink
+type SinkStatsProvider interface {
+	SinkStats() SinkStats
+}
+
+// ItemError 批次中单条日志的失败
+type ItemError struct {
+	Index int   // 条目在 WriteBatch 参数中的下标
+	Err   error // 失败原因
+}
+
+// BatchError 批次部分失败，Retry 中的条目会被重试，Failed 中的条目直接交给失败处理函数和死信
+type BatchError struct {
+	Retry  []ItemError
+	Failed []ItemError
+}
+
+func (e *BatchError) Error() string {
+	last := e.lastError()
+	return fmt.Sprintf("%d entries failed (%d retryable), last: %v", len(e.Retry)+len(e.Failed), len(e.Retry), last)
+}
+
+// lastError 返回最后一个失败原因，优先取可重试的失败
+func (e *BatchError) lastError() error {
+	if len(e.Retry) > 0 {
+		return e.Retry[len(e.Retry)-1].Err
+	}
+	if len(e.Failed) > 0 {
+		return e.Failed[len(e.Failed)-1].Err
+	}
+	return nil
+}
+
+// BatchConfig BatchWriter 配置
+type BatchConfig struct {
+	Name               string         `json:"name,omitempty"`                  // 写入器名称，用于错误输出
+	BufferSize         int            `json:"buffer_size"`                     // 单个批次的条目数，缓冲区达到此大小时立即刷新
+	FlushInterval      time.Duration  `json:"flush_interval"`                  // 刷新间隔
+	MinLevel           Level          `json:"min_level,omitempty"`             // 最低写入级别，低于此级别的日志直接丢弃
+	AtomicLevel        *AtomicLevel   `json:"-"`                               // 可在运行时修改的最低级别，设置后忽略 MinLevel
+	Processors         []Processor    `json:"-"`                               // 日志进入缓冲区前依次执行的处理器
+	MaxBufferedEntries int            `json:"max_buffered_entries,omitempty"`  // 缓冲区最大条目数
+	MaxBufferedBytes   int64          `json:"max_buffered_bytes,omitempty"`    // 缓冲区最大字节数（估算值），0 表示不限制
+	OverflowPolicy     OverflowPolicy `json:"overflow_policy,omitempty"`       // 缓冲区满时的处理策略
+	BlockTimeout       time.Duration  `json:"block_timeout,omitempty"`         // OverflowBlock 策略下的最长等待时间
+	Retry              *RetryPolicy   `json:"retry,omitempty"`                 // 失败重试策略，nil 使用默认策略
+	FlushWorkers       int            `json:"flush_workers,omitempty"`         // 并发发送的 worker 数
+	MaxInFlightBatches int            `json:"max_in_flight_batches,omitempty"` // 已封装但尚未发送完成的批次上限
+	SpoolDir           string         `json:"spool_dir,omitempty"`             // 磁盘队列目录，为空表示不启用
+	SpoolMaxBytes      int64          `json:"spool_max_bytes,omitempty"`       // 磁盘队列最大字节数
+	SpoolSegmentBytes  int64          `json:"spool_segment_bytes,omitempty"`   // 单个段文件的字节数
+
+	Service      string                 `json:"service,omitempty"`       // 服务名，作为顶层字段 service 写入每条日志
+	Environment  string                 `json:"environment,omitempty"`   // 环境，作为顶层字段 env 写入每条日志
+	Version      string                 `json:"version,omitempty"`       // 版本，作为顶层字段 version 写入每条日志
+	StaticFields map[string]interface{} `json:"static_fields,omitempty"` // 写入每条日志顶层的静态字段
+	RuntimeInfo  bool                   `json:"runtime_info,omitempty"`  // 是否附加主机名、进程号和 Go 构建信息
+
+	DisableCaller bool `json:"disable_caller,omitempty"` // 不记录调用位置
+	CallerSkip    int  `json:"caller_skip,omitempty"`    // 记录调用位置时额外跳过的栈帧数
+
+	Clock              Clock         `json:"-"`                             // 生成时间戳的时钟，nil 使用系统时钟
+	UTC                bool          `json:"utc,omitempty"`                 // 时间戳使用 UTC 时区，默认使用本地时区
+	TimestampPrecision time.Duration `json:"timestamp_precision,omitempty"` // 时间戳精度，0 表示纳秒
+	Sequence           bool          `json:"sequence,omitempty"`            // 是否为每条日志分配单调递增的序号
+
+	DeadLetter    DeadLetterSink                  `json:"-"` // 重试耗尽或被永久拒绝的日志的去处
+	OnError       func(err error, entries int)    `json:"-"` // 刷新失败时调用，未设置时限频输出到 stderr
+	OnItemFailure func(entry LogEntry, err error) `json:"-"` // 单条日志被永久拒绝或重试耗尽时调用
+}
+
+// DefaultBatchConfig 返回默认 BatchWriter 配置
+func DefaultBatchConfig() *BatchConfig {
+	return &BatchConfig{
+		BufferSize:         100,
+		FlushInterval:      5 * time.Second,
+		MaxBufferedEntries: defaultMaxBufferedEntries,
+		OverflowPolicy:     OverflowDropNewest,
+		BlockTimeout:       defaultBlockTimeout,
+		Retry:              DefaultRetryPolicy(),
+		FlushWorkers:       1,
+		MaxInFlightBatches: 2,
+	}
+}
+
+// BatchWriter 通用批量写入器，为任意 Sink 提供缓冲、并发发送、重试、死信和统计
+type BatchWriter struct {
+	sink       Sink
+	config     *BatchConfig
+	buffer     *entryBuffer
+	bufferSize int
+	level      *AtomicLevel
+	processors []Processor
+	metadata   map[string]interface{}
+	stamper    *stamper
+	retry      *RetryPolicy
+	errors     *errorReporter
+	stats      statsCollector
+	ctx        context.Context
+	cancel     context.CancelFunc
+	sendCtx    context.Context // 后台发送使用的 ctx，CloseContext 超时时取消
+	sendCancel context.CancelFunc
+	wg         sync.WaitGroup
+	flushChan  chan struct{}
+
+	batches  chan sealedBatch // 待发送的批次
+	slots    chan struct{}    // 在途批次配额，限制内存占用
+	tracker  *batchTracker
+	workerWg sync.WaitGroup
+	sealMu   sync.RWMutex
+	closed   bool
+
+	spool *spool        // 磁盘队列，未启用时为 nil
+	kept  atomic.Uint64 // 重试耗尽后保留在磁盘队列中、放回缓冲区的条目数
+
+	failureMu sync.Mutex
+	failure   error // 最近一次导致条目写入失败的错误
+}
+
+// NewBatchWriter 创建一个驱动 sink 的批量写入器，启用磁盘队列时会先重放上次未发送的日志
+func NewBatchWriter(sink Sink, config *BatchConfig) (*BatchWriter, error) {
+	if config == nil {
+		config = DefaultBatchConfig()
+	}
+	cfg := *config
+	if cfg.Name == "" {
+		cfg.Name = "batch"
+	}
+	if cfg.BufferSize <= 0 {
+		cfg.BufferSize = 100
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = 5 * time.Second
+	}
+	if cfg.MaxBufferedEntries <= 0 {
+		cfg.MaxBufferedEntries = defaultMaxBufferedEntries
+	}
+	if cfg.MaxBufferedEntries < cfg.BufferSize {
+		cfg.MaxBufferedEntries = cfg.BufferSize
+	}
+	if cfg.FlushWorkers <= 0 {
+		cfg.FlushWorkers = 1
+	}
+	if cfg.AtomicLevel == nil {
+		cfg.AtomicLevel = NewAtomicLevel(cfg.MinLevel)
+	}
+	if cfg.MaxInFlightBatches < cfg.FlushWorkers {
+		cfg.MaxInFlightBatches = cfg.FlushWorkers
+	}
+
+	metadata, err := buildMetadata(cfg.Service, cfg.Environment, cfg.Version, cfg.StaticFields, cfg.RuntimeInfo)
+	if err != nil {
+		return nil, err
+	}
+
+	var sp *spool
+	if cfg.SpoolDir != "" {
+		sp, err = openSpool(cfg.SpoolDir, cfg.SpoolMaxBytes, cfg.SpoolSegmentBytes)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	sendCtx, sendCancel := context.WithCancel(context.Background())
+	w := &BatchWriter{
+		sink:       sink,
+		config:     &cfg,
+		bufferSize: cfg.BufferSize,
+		level:      cfg.AtomicLevel,
+		processors: cfg.Processors,
+		metadata:   metadata,
+		stamper:    newStamper(cfg.Clock, cfg.UTC, cfg.TimestampPrecision, cfg.Sequence),
+		retry:      cfg.Retry.normalize(),
+		errors:     newErrorReporter(cfg.Name, cfg.OnError),
+		ctx:        ctx,
+		cancel:     cancel,
+		sendCtx:    sendCtx,
+		sendCancel: sendCancel,
+		flushChan:  make(chan struct{}, 1),
+		batches:    make(chan sealedBatch, cfg.MaxInFlightBatches),
+		slots:      make(chan struct{}, cfg.MaxInFlightBatches),
+		tracker:    newBatchTracker(),
+		spool:      sp,
+	}
+	w.buffer = newEntryBuffer(cfg.MaxBufferedEntries, cfg.MaxBufferedBytes,
+		cfg.OverflowPolicy, cfg.BlockTimeout, w.triggerFlush, w.onDrop)
+
+	// 重放磁盘队列中尚未确认的日志，超出缓冲区容量的部分在缓冲区腾出空间后继续读入
+	w.refill()
+
+	for i := 0; i < cfg.FlushWorkers; i++ {
+		w.workerWg.Add(1)
+		go w.worker()
+	}
+
+	w.wg.Add(1)
+	go w.flushLoop()
+
+	return w, nil
+}
+
+// log 内部日志方法
+func (w *BatchWriter) log(ctx context.Context, level string, content any, fields ...LogField) {
+	w.AddEntry(buildEntry(ctx, level, content, fields))
+}
+
+// Log 写入日志（公开方法，供外部直接调用）
+func (w *BatchWriter) Log(level string, content any, fields ...LogField) {
+	w.log(context.Background(), level, content, fields...)
+}
+
+// Info 写入 info 级别日志
+func (w *BatchWriter) Info(content any, fields ...LogField) {
+	w.log(context.Background(), "info", content, fields...)
+}
+
+// Error 写入 error 级别日志
+func (w *BatchWriter) Error(content any, fields ...LogField) {
+	w.log(context.Background(), "error", content, fields...)
+}
+
+// Debug 写入 debug 级别日志
+func (w *BatchWriter) Debug(content any, fields ...LogField) {
+	w.log(context.Background(), "debug", content, fields...)
+}
+
+// Warn 写入 warn 级别日志
+func (w *BatchWriter) Warn(content any, fields ...LogField) {
+	w.log(context.Background(), "warn", content, fields...)
+}
+
+// LogCtx 使用 ctx 写入日志
+func (w *BatchWriter) LogCtx(ctx context.Context, level string, content any, fields ...LogField) {
+	w.log(ctx, level, content, fields...)
+}
+
+// InfoCtx 使用 ctx 写入 info 级别日志
+func (w *BatchWriter) InfoCtx(ctx context.Context, content any, fields ...LogField) {
+	w.log(ctx, "info", content, fields...)
+}
+
+// ErrorCtx 使用 ctx 写入 error 级别日志
+func (w *BatchWriter) ErrorCtx(ctx context.Context, content any, fields ...LogField) {
+	w.log(ctx, "error", content, fields...)
+}
+
+// DebugCtx 使用 ctx 写入 debug 级别日志
+func (w *BatchWriter) DebugCtx(ctx context.Context, content any, fields ...LogField) {
+	w.log(ctx, "debug", content, fields...)
+}
+
+// WarnCtx 使用 ctx 写入 warn 级别日志
+func (w *BatchWriter) WarnCtx(ctx context.Context, content any, fields ...LogField) {
+	w.log(ctx, "warn", content, fields...)
+}
+
+// WriteEntry 写入已构建好的日志条目，实现 EntryWriter 接口
+func (w *BatchWriter) WriteEntry(entry LogEntry) {
+	w.AddEntry(entry)
+}
+
+// AddEntry 添加日志条目到缓冲区（导出供适配器使用），依次经过级别过滤和处理器，启用磁盘队列时先写入磁盘；
+// 条目没有时间戳或 Caller 时按配置生成
+func (w *BatchWriter) AddEntry(entry LogEntry) {
+	if !w.level.Enabled(entry.Level) {
+		return
+	}
+	w.stamper.stamp(&entry)
+	if entry.Caller == "" && !w.config.DisableCaller {
+		entry.Caller = FindCaller(w.config.CallerSkip)
+	}
+	if !ApplyProcessors(w.processors, &entry) {
+		return
+	}
+	w.attachMetadata(&entry)
+	if w.spool != nil {
+		var n int
+		admitted := false
+		_, err := w.spool.append(entry, func(seg uint64) bool {
+			n, admitted = w.buffer.tryAdd(entry, seg)
+			return admitted
+		})
+		if err == nil {
+			// 缓冲区已满或磁盘队列有积压时条目只保存在磁盘上，稍后按写入顺序读入缓冲区
+			w.stats.recordAccepted(entry.Level)
+			if !admitted || n >= w.bufferSize {
+				w.triggerFlush()
+			}
+			return
+		}
+		// 磁盘队列写满或写入失败时仅保存在内存中
+		w.errors.report(fmt.Errorf("failed to spool log entry: %w", err), 1)
+	}
+	n, ok := w.buffer.add(entry, 0)
+	if !ok {
+		return
+	}
+	w.stats.recordAccepted(entry.Level)
+	if n >= w.bufferSize {
+		w.triggerFlush()
+	}
+}
+
+// attachMetadata 附加静态字段，条目已有的同名字段优先
+func (w *BatchWriter) attachMetadata(entry *LogEntry) {
+	if len(w.metadata) == 0 {
+		return
+	}
+	if len(entry.Metadata) == 0 {
+		entry.Metadata = w.metadata
+		return
+	}
+	merged := make(map[string]interface{}, len(w.metadata)+len(entry.Metadata))
+	for key, value := range w.metadata {
+		merged[key] = value
+	}
+	for key, value := range entry.Metadata {
+		merged[key] = value
+	}
+	entry.Metadata = merged
+}
+
+// onDrop 记录因缓冲区溢出被丢弃的条目，并从磁盘队列中确认
+func (w *BatchWriter) onDrop(item bufferedEntry) {
+	w.stats.recordDropped(item.entry)
+	w.ack(item)
+}
+
+// ack 确认条目已处理完成（写入成功、转入死信或被丢弃），允许删除磁盘队列中的段文件
+func (w *BatchWriter) ack(items ...bufferedEntry) {
+	if w.spool == nil {
+		return
+	}
+	segs := make([]uint64, 0, len(items))
+	for _, item := range items {
+		if item.seg != 0 {
+			segs = append(segs, item.seg)
+		}
+	}
+	w.spool.ack(segs...)
+}
+
+// refill 从磁盘队列读入积压的日志，直到缓冲区满或积压读完，返回读入的条目数
+func (w *BatchWriter) refill() int {
+	if w.spool == nil {
+		return 0
+	}
+	n, err := w.spool.load(func(item spooledEntry) bool {
+		if _, ok := w.buffer.tryAdd(item.entry, item.seg); !ok {
+			return false
+		}
+		if item.replayed {
+			w.stats.recordAccepted(item.entry.Level)
+		}
+		return true
+	})
+	if err != nil {
+		w.errors.report(err, w.spool.pendingBacklog())
+	}
+	if n > 0 {
+		w.triggerFlush()
+	}
+	return n
+}
+
+// backlog 返回只保存在磁盘队列中、尚未读入缓冲区的条目数
+func (w *BatchWriter) backlog() int {
+	if w.spool == nil {
+		return 0
+	}
+	return w.spool.pendingBacklog()
+}
+
+// triggerFlush 通知后台 goroutine 刷新缓冲区
+func (w *BatchWriter) triggerFlush() {
+	select {
+	case w.flushChan <- struct{}{}:
+	default:
+	}
+}
+
+// AtomicLevel 返回写入器的最低级别，可用于运行时调整
+func (w *BatchWriter) AtomicLevel() *AtomicLevel {
+	return w.level
+}
+
+// Dropped 返回因缓冲区溢出而被丢弃的日志条目数
+func (w *BatchWriter) Dropped() uint64 {
+	return w.buffer.dropped.Load()
+}
+
+// Stats 返回写入器运行统计
+func (w *BatchWriter) Stats() Stats {
+	stats := w.stats.snapshot()
+	stats.Dropped = w.buffer.dropped.Load()
+	stats.BufferDepth = w.buffer.len()
+	if p, ok := w.sink.(SinkStatsProvider); ok {
+		ss := p.SinkStats()
+		stats.BytesRaw = ss.BytesRaw
+		stats.BytesSent = ss.BytesSent
+	}
+	return stats
+}
+
+// Close 关闭写入器，等待所有缓冲的日志写入完成
+func (w *BatchWriter) Close() error {
+	return w.CloseContext(context.Background())
+}
+
+// CloseContext 关闭写入器，ctx 结束或有条目重试耗尽后保留在磁盘队列中时返回 *UnsentError；
+// 未发送的日志已写入磁盘队列的保留在磁盘上供下次启动时重放，其余（未启用磁盘队列、磁盘队列已满或写入失败）转入死信
+func (w *BatchWriter) CloseContext(ctx context.Context) error {
+	failedBefore := w.stats.failed.Load()
+	w.cancel()
+	defer w.closeSink()
+	defer w.sendCancel()
+	w.wg.Wait()
+
+	// 将剩余日志（包括磁盘队列中的积压）封装为批次后关闭队列，worker 处理完队列后退出
+	err := w.drain(ctx)
+	w.lockSeal(ctx)
+	if w.closed {
+		w.sealMu.Unlock()
+		return nil
+	}
+	w.closed = true
+	close(w.batches)
+	w.sealMu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		w.workerWg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		// 中止正在进行的发送，未发送的条目会被放回缓冲区
+		w.sendCancel()
+		<-done
+	}
+
+	if ctx.Err() != nil {
+		err = ctx.Err()
+	}
+	if unsent, backlog := w.buffer.take(), w.backlog(); len(unsent)+backlog > 0 {
+		if err == nil {
+			err = w.lastError()
+		}
+		w.deadLetter(entriesOf(unspooled(unsent)), w.target(), err, 0)
+		return &UnsentError{Unsent: len(unsent) + backlog, Err: err}
+	}
+	return w.failedSince(failedBefore)
+}
+
+// lockSeal 获取 sealMu 的写锁；并发的 Flush 可能持有读锁阻塞在 seal 中，
+// ctx 结束时先中止正在进行的发送，使其尽快释放读锁
+func (w *BatchWriter) lockSeal(ctx context.Context) {
+	locked := make(chan struct{})
+	go func() {
+		w.sealMu.Lock()
+		close(locked)
+	}()
+	select {
+	case <-locked:
+	case <-ctx.Done():
+		w.sendCancel()
+		<-locked
+	}
+}
+
+// unspooled 返回未写入磁盘队列的条目
+func unspooled(items []bufferedEntry) []bufferedEntry {
+	var rest []bufferedEntry
+	for _, item := range items {
+		if item.seg == 0 {
+			rest = append(rest, item)
+		}
+	}
+	return rest
+}
+
+// closeSink 关闭磁盘队列和实现了 io.Closer 的 Sink
+func (w *BatchWriter) closeSink() {
+	if w.spool != nil {
+		w.spool.close()
+	}
+	if c, ok := w.sink.(io.Closer); ok {
+		c.Close()
+	}
+}
+
+// Flush 立即发送缓冲区中的日志并等待此前的批次完成，ctx 结束或有条目重试耗尽后保留在磁盘队列中时
+// 返回 *UnsentError（未发送的条目仍会在后台继续发送）
+func (w *BatchWriter) Flush(ctx context.Context) error {
+	failedBefore := w.stats.failed.Load()
+	keptBefore := w.kept.Load()
+	err := w.drain(ctx)
+	if err == nil {
+		err = w.tracker.wait(ctx, w.tracker.last())
+	}
+	if err == nil && w.kept.Load() > keptBefore {
+		err = w.lastError()
+	}
+	if err != nil {
+		return &UnsentError{Unsent: w.buffer.len() + w.tracker.entries() + w.backlog(), Err: err}
+	}
+	return w.failedSince(failedBefore)
+}
+
+// drain 封装缓冲区中的日志，并继续读入、封装磁盘队列中积压的日志，
+// 直到积压读完、ctx 结束或有条目重试耗尽（剩余的积压保留在磁盘上）
+func (w *BatchWriter) drain(ctx context.Context) error {
+	kept := w.kept.Load()
+	err := w.seal(ctx)
+	for err == nil && w.kept.Load() == kept && w.refill() > 0 {
+		err = w.seal(ctx)
+	}
+	return err
+}
+
+// lastError 返回最近一次刷新错误
+func (w *BatchWriter) lastError() error {
+	if msg := w.stats.snapshot().LastError; msg != "" {
+		return errors.New(msg)
+	}
+	return errors.New("log entries left in spool")
+}
+
+// failedSince 若自 before 以来有条目写入失败，返回包含最近一次失败原因的 error
+func (w *BatchWriter) failedSince(before uint64) error {
+	n := w.stats.failed.Load() - before
+	if n == 0 {
+		return nil
+	}
+	w.failureMu.Lock()
+	failure := w.failure
+	w.failureMu.Unlock()
+	return fmt.Errorf("%d log entries failed to flush: %w", n, failure)
+}
+
+// target 返回 Sink 的写入目标
+func (w *BatchWriter) target() string {
+	if t, ok := w.sink.(TargetSink); ok {
+		return t.Target()
+	}
+	return w.config.Name
+}
+
+// seal 将调用时缓冲区中的日志按 BufferSize 切分为批次交给发送 worker，
+// 在途批次达到 MaxInFlightBatches 时等待；封装期间放回缓冲区的条目留到下次封装
+func (w *BatchWriter) seal(ctx context.Context) error {
+	w.sealMu.RLock()
+	defer w.sealMu.RUnlock()
+	if w.closed {
+		return nil
+	}
+
+	for n := w.buffer.len(); n > 0; {
+		select {
+		case w.slots <- struct{}{}:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+
+		items := w.buffer.takeN(min(w.bufferSize, n))
+		if len(items) == 0 {
+			<-w.slots
+			return nil
+		}
+		n -= len(items)
+		w.batches <- sealedBatch{seq: w.tracker.start(len(items)), items: items}
+	}
+	return nil
+}
+
+// worker 发送 worker，从队列中取出批次并发送
+func (w *BatchWriter) worker() {
+	defer w.workerWg.Done()
+
+	for batch := range w.batches {
+		unsent, _ := w.send(w.sendCtx, batch.items)
+		w.complete(batch.items, unsent)
+		w.tracker.finish(batch.seq)
+		<-w.slots
+	}
+}
+
+// complete 将未发送的条目放回缓冲区，确认其余条目
+func (w *BatchWriter) complete(items []bufferedEntry, unsent []int) {
+	if len(unsent) == 0 {
+		w.ack(items...)
+		return
+	}
+	requeue := make([]bufferedEntry, 0, len(unsent))
+	done := make([]bufferedEntry, 0, len(items)-len(unsent))
+	next := 0
+	for i, item := range items {
+		if next < len(unsent) && unsent[next] == i {
+			requeue = append(requeue, item)
+			next++
+		} else {
+			done = append(done, item)
+		}
+	}
+	w.ack(done...)
+	w.buffer.requeue(requeue)
+}
+
+// send 将一批日志写入 Sink，按策略重试失败的条目，返回需要放回缓冲区的条目下标（升序）：
+// 因 ctx 结束而未发送的条目，以及启用磁盘队列时重试耗尽的已写入磁盘的条目
+func (w *BatchWriter) send(ctx context.Context, items []bufferedEntry) ([]int, error) {
+	start := time.Now()
+	target := w.target()
+	entries := entriesOf(items)
+	pending := make([]int, len(entries))
+	for i := range pending {
+		pending[i] = i
+	}
+	itemErrs := make(map[int]error) // 最近一次以可重试错误失败的条目
+	var failed []LogEntry
+	var rejection error // 最近一个被永久拒绝的条目的失败原因
+
+	attempts, err := w.retry.do(ctx, func(attempt int) error {
+		if attempt > 1 {
+			w.stats.retried.Add(uint64(len(pending)))
+		}
+		batch := make([]LogEntry, len(pending))
+		for i, idx := range pending {
+			batch[i] = entries[idx]
+		}
+
+		err := w.sink.WriteBatch(ctx, batch)
+		var be *BatchError
+		if !errors.As(err, &be) {
+			clear(itemErrs)
+			if err == nil {
+				pending = nil
+			}
+			return err
+		}
+
+		for _, f := range be.Failed {
+			entry := entries[pending[f.Index]]
+			w.handleItemFailure(entry, f.Err, target, attempt)
+			failed = append(failed, entry)
+			rejection = f.Err
+		}
+
+		// 仅重试以可重试错误失败的条目
+		clear(itemErrs)
+		next := make([]int, 0, len(be.Retry))
+		for _, f := range be.Retry {
+			next = append(next, pending[f.Index])
+			itemErrs[pending[f.Index]] = f.Err
+		}
+		pending = next
+		if len(pending) > 0 {
+			return Retryable(be)
+		}
+		return nil
+	})
+
+	// ctx 结束导致的失败不计入失败数，由调用方决定放回缓冲区或转入死信
+	if err != nil && ctx.Err() != nil {
+		w.stats.recordFlush(time.Since(start), entries, failed, pick(entries, pending), err)
+		sort.Ints(pending)
+		return pending, err
+	}
+
+	// 可重试错误导致重试耗尽时已写入磁盘队列的条目不确认，放回缓冲区等待下次刷新，
+	// 只有写入成功或被永久拒绝的条目才从磁盘删除
+	var kept []int
+	if err != nil && w.spool != nil && IsRetryable(err) {
+		rest := make([]int, 0, len(pending))
+		for _, idx := range pending {
+			if items[idx].seg != 0 {
+				kept = append(kept, idx)
+			} else {
+				rest = append(rest, idx)
+			}
+		}
+		pending = rest
+	}
+
+	remaining := pick(entries, pending)
+	rejected := len(failed)
+	if err != nil {
+		failed = append(failed, remaining...)
+	}
+	// 仅部分条目被永久拒绝时以拒绝原因作为本次刷新的错误，记录到 Stats.LastError
+	flushErr := err
+	if flushErr == nil && rejected > 0 {
+		flushErr = fmt.Errorf("%d log entries permanently rejected by %s: %w", rejected, target, rejection)
+	}
+	if len(failed) > 0 {
+		w.failureMu.Lock()
+		w.failure = flushErr
+		w.failureMu.Unlock()
+	}
+	w.stats.recordFlush(time.Since(start), entries, failed, pick(entries, kept), flushErr)
+
+	// 重试耗尽或被永久拒绝的条目交给失败处理函数和死信目的地
+	if err != nil {
+		if len(itemErrs) > 0 {
+			for _, idx := range pending {
+				w.handleItemFailure(entries[idx], itemErrs[idx], target, attempts)
+			}
+		} else {
+			w.deadLetter(remaining, target, err, attempts)
+		}
+		w.errors.report(err, len(failed)+len(kept))
+	} else if rejected > 0 {
+		w.errors.report(flushErr, rejected)
+	}
+	if len(kept) > 0 {
+		w.kept.Add(uint64(len(kept)))
+		sort.Ints(kept)
+		return kept, err
+	}
+	return nil, err
+}
+
+// pick 返回 entries 中下标为 idx 的条目
+func pick(entries []LogEntry, idx []int) []LogEntry {
+	picked := make([]LogEntry, len(idx))
+	for i, j := range idx {
+		picked[i] = entries[j]
+	}
+	return picked
+}
+
+// handleItemFailure 处理无法写入的单条日志
+func (w *BatchWriter) handleItemFailure(entry LogEntry, err error, target string, attempts int) {
+	if w.config.OnItemFailure != nil {
+		w.config.OnItemFailure(entry, err)
+	}
+	w.deadLetter([]LogEntry{entry}, target, err, attempts)
+}
+
+// deadLetter 将条目交给死信目的地，写入死信失败时上报错误并计入统计
+func (w *BatchWriter) deadLetter(entries []LogEntry, target string, err error, attempts int) {
+	failed, dlErr := sendDeadLetters(w.config.DeadLetter, entries, target, err, attempts)
+	if failed == 0 {
+		return
+	}
+	dlErr = fmt.Errorf("failed to write %d dead letters: %w", failed, dlErr)
+	w.stats.recordDeadLetterFailure(failed, dlErr)
+	w.errors.report(dlErr, failed)
+}
+
+// flushLoop 刷新循环（后台 goroutine），将缓冲区封装为批次
+func (w *BatchWriter) flushLoop() {
+	defer w.wg.Done()
+
+	ticker := time.NewTicker(w.config.FlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.ctx.Done():
+			return
+		case <-ticker.C:
+		case <-w.flushChan:
+		}
+		w.refill()
+		w.seal(w.ctx)
+	}
+}
@@ -0,0 +1,133 @@
+package writer
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// rejectFirstSink 测试用 Sink，永久拒绝每批的第一条，其余写入成功
+type rejectFirstSink struct {
+	reason error
+}
+
+func (s rejectFirstSink) WriteBatch(ctx context.Context, entries []LogEntry) error {
+	return &BatchError{Failed: []ItemError{{Index: 0, Err: s.reason}}}
+}
+
+// testBatchConfig 返回只在手动 Flush 时发送、不输出错误的配置
+func testBatchConfig() *BatchConfig {
+	config := DefaultBatchConfig()
+	config.FlushInterval = time.Hour
+	config.OnError = func(err error, entries int) {}
+	return config
+}
+
+func TestBatchWriterRecordsItemRejectionReason(t *testing.T) {
+	reason := errors.New("mapper_parsing_exception: failed to parse field [age]")
+	w, err := NewBatchWriter(rejectFirstSink{reason: reason}, testBatchConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	w.Info("bad")
+	w.Info("good")
+	w.Flush(context.Background())
+
+	stats := w.Stats()
+	if stats.Failed != 1 || stats.Flushed != 1 {
+		t.Fatalf("Failed = %d, Flushed = %d, want 1 and 1", stats.Failed, stats.Flushed)
+	}
+	if !strings.Contains(stats.LastError, reason.Error()) {
+		t.Fatalf("LastError = %q, want it to contain %q", stats.LastError, reason)
+	}
+}
+
+func TestBatchWriterFlushErrorIncludesRejectionReason(t *testing.T) {
+	reason := errors.New("mapper_parsing_exception: failed to parse field [age]")
+	w, err := NewBatchWriter(rejectFirstSink{reason: reason}, testBatchConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	w.Info("bad")
+	err = w.Flush(context.Background())
+	if err == nil {
+		t.Fatal("Flush() error = nil, want the rejection")
+	}
+	if want := "1 log entries failed to flush: 1 log entries permanently rejected by batch: " + reason.Error(); err.Error() != want {
+		t.Fatalf("Flush() error = %q, want %q", err, want)
+	}
+	if !errors.Is(err, reason) {
+		t.Fatalf("Flush() error = %v, want it to wrap the rejection reason", err)
+	}
+}
+
+func TestBatchWriterCloseContextHonorsDeadlineDuringFlush(t *testing.T) {
+	config := testBatchConfig()
+	config.BufferSize = 1
+	config.MaxInFlightBatches = 1
+	w, err := NewBatchWriter(blockingSink{}, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		w.Info("message")
+	}
+
+	// Flush 在 seal 中等待在途批次配额，持有 sealMu 的读锁
+	flushed := make(chan error, 1)
+	go func() { flushed <- w.Flush(context.Background()) }()
+	time.Sleep(50 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	closed := make(chan error, 1)
+	go func() { closed <- w.CloseContext(ctx) }()
+
+	select {
+	case err := <-closed:
+		var unsent *UnsentError
+		if !errors.As(err, &unsent) {
+			t.Fatalf("CloseContext() error = %v, want *UnsentError", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("CloseContext() ignored its deadline while Flush was blocked")
+	}
+	<-flushed
+}
+
+func TestBatchWriterReportsDeadLetterFailures(t *testing.T) {
+	var reported []error
+	config := testBatchConfig()
+	config.OnError = func(err error, entries int) {
+		reported = append(reported, err)
+	}
+	config.DeadLetter = DeadLetterFunc(func(letter DeadLetter) error {
+		return errors.New("disk full")
+	})
+	w, err := NewBatchWriter(rejectFirstSink{reason: errors.New("mapper_parsing_exception")}, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	w.Info("bad")
+	w.Flush(context.Background())
+
+	stats := w.Stats()
+	if stats.DeadLetterErrors != 1 {
+		t.Fatalf("DeadLetterErrors = %d, want 1", stats.DeadLetterErrors)
+	}
+	var found bool
+	for _, err := range reported {
+		found = found || strings.Contains(err.Error(), "failed to write 1 dead letters: disk full")
+	}
+	if !found {
+		t.Fatalf("OnError calls = %v, want the dead letter failure", reported)
+	}
+}
@@ -0,0 +1,262 @@
+package writer
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// bufferedEntry 缓冲区中的日志条目及其估算大小
+type bufferedEntry struct {
+	entry LogEntry
+	size  int64
+	seg   uint64 // 所在的磁盘队列段，0 表示未写入磁盘
+}
+
+// entryBuffer 有界日志缓冲区，超出限制时按 OverflowPolicy 处理
+type entryBuffer struct {
+	mu           sync.Mutex
+	entries      []bufferedEntry
+	bytes        int64
+	maxEntries   int
+	maxBytes     int64
+	policy       OverflowPolicy
+	blockTimeout time.Duration
+	space        chan struct{} // take 之后关闭，用于唤醒阻塞的写入方
+	onFull       func()
+	onDrop       func(item bufferedEntry)
+	dropped      atomic.Uint64
+}
+
+// newEntryBuffer 创建有界缓冲区，onFull 在缓冲区满时被调用（用于触发刷新），onDrop 在条目被丢弃时调用
+func newEntryBuffer(maxEntries int, maxBytes int64, policy OverflowPolicy, blockTimeout time.Duration, onFull func(), onDrop func(item bufferedEntry)) *entryBuffer {
+	if policy == "" {
+		policy = OverflowDropNewest
+	}
+	if blockTimeout <= 0 {
+		blockTimeout = defaultBlockTimeout
+	}
+	return &entryBuffer{
+		maxEntries:   maxEntries,
+		maxBytes:     maxBytes,
+		policy:       policy,
+		blockTimeout: blockTimeout,
+		space:        make(chan struct{}),
+		onFull:       onFull,
+		onDrop:       onDrop,
+	}
+}
+
+// add 添加日志条目，seg 为条目所在的磁盘队列段；返回添加后的缓冲区长度，条目被丢弃时 ok 为 false。
+// onDrop 在释放锁之后调用，其中可以确认磁盘队列而不会与磁盘队列的锁形成环
+func (b *entryBuffer) add(entry LogEntry, seg uint64) (n int, ok bool) {
+	item := bufferedEntry{entry: entry, size: estimateEntrySize(entry), seg: seg}
+
+	b.mu.Lock()
+	n, ok, dropped := b.insert(item)
+	b.mu.Unlock()
+
+	b.dropped.Add(uint64(len(dropped)))
+	if b.onDrop != nil {
+		for _, d := range dropped {
+			b.onDrop(d)
+		}
+	}
+	return n, ok
+}
+
+// insert 按 OverflowPolicy 加入条目，返回被丢弃的条目（可能包括 item 本身），调用时需持有锁
+func (b *entryBuffer) insert(item bufferedEntry) (n int, ok bool, dropped []bufferedEntry) {
+	if b.full(item.size) {
+		switch b.policy {
+		case OverflowBlock:
+			if !b.waitForSpace(item.size) {
+				return len(b.entries), false, []bufferedEntry{item}
+			}
+		case OverflowDropOldest:
+			for b.full(item.size) && len(b.entries) > 0 {
+				dropped = append(dropped, b.evict(0))
+			}
+		case OverflowDropByLevel:
+			if !keepOnOverflow(item.entry.Level) {
+				return len(b.entries), false, []bufferedEntry{item}
+			}
+			for b.full(item.size) && len(b.entries) > 0 {
+				dropped = append(dropped, b.evict(b.evictionCandidate()))
+			}
+		default:
+			return len(b.entries), false, []bufferedEntry{item}
+		}
+	}
+
+	b.entries = append(b.entries, item)
+	b.bytes += item.size
+	return len(b.entries), true, dropped
+}
+
+// tryAdd 缓冲区未满时添加条目，满时不按 OverflowPolicy 处理，直接返回 false
+func (b *entryBuffer) tryAdd(entry LogEntry, seg uint64) (n int, ok bool) {
+	item := bufferedEntry{entry: entry, size: estimateEntrySize(entry), seg: seg}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.full(item.size) {
+		return len(b.entries), false
+	}
+	b.entries = append(b.entries, item)
+	b.bytes += item.size
+	return len(b.entries), true
+}
+
+// full 判断再加入 size 字节后是否超出限制
+func (b *entryBuffer) full(size int64) bool {
+	if b.maxEntries > 0 && len(b.entries) >= b.maxEntries {
+		return true
+	}
+	return b.maxBytes > 0 && len(b.entries) > 0 && b.bytes+size > b.maxBytes
+}
+
+// waitForSpace 等待缓冲区腾出空间，调用时需持有锁
+func (b *entryBuffer) waitForSpace(size int64) bool {
+	timer := time.NewTimer(b.blockTimeout)
+	defer timer.Stop()
+
+	for b.full(size) {
+		space := b.space
+		b.mu.Unlock()
+		if b.onFull != nil {
+			b.onFull()
+		}
+		select {
+		case <-space:
+			b.mu.Lock()
+		case <-timer.C:
+			b.mu.Lock()
+			return !b.full(size)
+		}
+	}
+	return true
+}
+
+// evictionCandidate 返回最早的非高优先级条目下标，不存在时返回 0
+func (b *entryBuffer) evictionCandidate() int {
+	for i, item := range b.entries {
+		if !keepOnOverflow(item.entry.Level) {
+			return i
+		}
+	}
+	return 0
+}
+
+// evict 移除并返回指定下标的条目
+func (b *entryBuffer) evict(i int) bufferedEntry {
+	item := b.entries[i]
+	b.bytes -= item.size
+	b.entries = append(b.entries[:i], b.entries[i+1:]...)
+	return item
+}
+
+// take 取出缓冲区中的全部条目
+func (b *entryBuffer) take() []bufferedEntry {
+	return b.takeN(0)
+}
+
+// takeN 从缓冲区头部取出最多 n 条条目，n <= 0 时取出全部
+func (b *entryBuffer) takeN(n int) []bufferedEntry {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if len(b.entries) == 0 {
+		return nil
+	}
+	if n <= 0 || n > len(b.entries) {
+		n = len(b.entries)
+	}
+
+	items := make([]bufferedEntry, n)
+	copy(items, b.entries[:n])
+	for _, item := range items {
+		b.bytes -= item.size
+	}
+	rest := copy(b.entries, b.entries[n:])
+	clear(b.entries[rest:])
+	b.entries = b.entries[:rest]
+
+	close(b.space)
+	b.space = make(chan struct{})
+	return items
+}
+
+// requeue 将未发送的条目放回缓冲区头部，不受容量限制（数量不超过在途批次中的条目数）
+func (b *entryBuffer) requeue(items []bufferedEntry) {
+	if len(items) == 0 {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, item := range items {
+		b.bytes += item.size
+	}
+	b.entries = append(append(make([]bufferedEntry, 0, len(items)+len(b.entries)), items...), b.entries...)
+}
+
+// len 返回缓冲区当前条目数
+func (b *entryBuffer) len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.entries)
+}
+
+// entriesOf 返回缓冲条目中的日志
+func entriesOf(items []bufferedEntry) []LogEntry {
+	entries := make([]LogEntry, len(items))
+	for i, item := range items {
+		entries[i] = item.entry
+	}
+	return entries
+}
+
+// keepOnOverflow 判断级别是否应在 OverflowDropByLevel 策略下优先保留（error 及以上）
+func keepOnOverflow(level string) bool {
+	return LevelOf(level) >= LevelError
+}
+
+// estimateEntrySize 估算日志条目序列化后的字节数
+func estimateEntrySize(entry LogEntry) int64 {
+	size := len(entry.Timestamp) + len(entry.Level) + len(entry.Content) +
+		len(entry.Duration) + len(entry.Trace) + len(entry.Span) + len(entry.Caller) + 64
+	for key, value := range entry.Fields {
+		size += len(key) + estimateValueSize(value) + 4
+	}
+	for key, value := range entry.Metadata {
+		size += len(key) + estimateValueSize(value) + 4
+	}
+	return int64(size)
+}
+
+// estimateValueSize 估算字段值序列化后的字节数
+func estimateValueSize(value any) int {
+	switch val := value.(type) {
+	case string:
+		return len(val) + 2
+	case []byte:
+		return len(val)
+	case error:
+		return len(val.Error()) + 2
+	case map[string]interface{}:
+		size := 2
+		for k, v := range val {
+			size += len(k) + estimateValueSize(v) + 4
+		}
+		return size
+	case []interface{}:
+		size := 2
+		for _, v := range val {
+			size += estimateValueSize(v) + 1
+		}
+		return size
+	default:
+		return 16
+	}
+}
@@ -0,0 +1,139 @@
+package writer
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// bufferContents 返回缓冲区中各条目的 content
+func bufferContents(b *entryBuffer) []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	contents := make([]string, len(b.entries))
+	for i, item := range b.entries {
+		contents[i] = item.entry.Content
+	}
+	return contents
+}
+
+func TestEntryBufferOverflowPolicies(t *testing.T) {
+	tests := []struct {
+		policy  OverflowPolicy
+		levels  []string
+		want    []string
+		dropped []string
+	}{
+		{
+			policy:  OverflowDropNewest,
+			levels:  []string{"info", "info", "info", "info", "info"},
+			want:    []string{"0", "1", "2"},
+			dropped: []string{"3", "4"},
+		},
+		{
+			policy:  OverflowDropOldest,
+			levels:  []string{"info", "info", "info", "info", "info"},
+			want:    []string{"2", "3", "4"},
+			dropped: []string{"0", "1"},
+		},
+		{
+			policy:  OverflowDropByLevel,
+			levels:  []string{"info", "error", "info", "error", "info", "error", "fatal"},
+			want:    []string{"3", "5", "6"},
+			dropped: []string{"0", "4", "2", "1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.policy), func(t *testing.T) {
+			var dropped []string
+			b := newEntryBuffer(3, 0, tt.policy, 0, nil, func(item bufferedEntry) {
+				dropped = append(dropped, item.entry.Content)
+			})
+			for i, level := range tt.levels {
+				b.add(LogEntry{Level: level, Content: fmt.Sprint(i)}, 0)
+			}
+			if got := bufferContents(b); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("buffer = %v, want %v", got, tt.want)
+			}
+			if !reflect.DeepEqual(dropped, tt.dropped) {
+				t.Fatalf("dropped = %v, want %v", dropped, tt.dropped)
+			}
+			if n := b.dropped.Load(); n != uint64(len(tt.dropped)) {
+				t.Fatalf("dropped count = %d, want %d", n, len(tt.dropped))
+			}
+		})
+	}
+}
+
+func TestEntryBufferMaxBytes(t *testing.T) {
+	entry := LogEntry{Level: "info", Content: "message"}
+	size := estimateEntrySize(entry)
+	b := newEntryBuffer(100, 2*size, OverflowDropNewest, 0, nil, nil)
+	for i := 0; i < 4; i++ {
+		b.add(entry, 0)
+	}
+	if n := b.len(); n != 2 {
+		t.Fatalf("len = %d, want 2 entries within MaxBufferedBytes", n)
+	}
+	if n := b.dropped.Load(); n != 2 {
+		t.Fatalf("dropped = %d, want 2", n)
+	}
+}
+
+func TestEntryBufferBlockUnblocksWhenSpaceFrees(t *testing.T) {
+	flushes := make(chan struct{}, 10)
+	b := newEntryBuffer(1, 0, OverflowBlock, time.Minute, func() { flushes <- struct{}{} }, nil)
+	b.add(LogEntry{Content: "first"}, 0)
+
+	added := make(chan bool, 1)
+	go func() {
+		_, ok := b.add(LogEntry{Content: "second"}, 0)
+		added <- ok
+	}()
+
+	select {
+	case <-flushes:
+	case <-time.After(time.Second):
+		t.Fatal("blocked add did not trigger a flush")
+	}
+	select {
+	case <-added:
+		t.Fatal("add returned while the buffer was full")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	if items := b.takeN(1); len(items) != 1 || items[0].entry.Content != "first" {
+		t.Fatalf("takeN(1) = %v, want the first entry", items)
+	}
+	select {
+	case ok := <-added:
+		if !ok {
+			t.Fatal("add = false, want true after space was freed")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("add stayed blocked after space was freed")
+	}
+	if got := bufferContents(b); !reflect.DeepEqual(got, []string{"second"}) {
+		t.Fatalf("buffer = %v, want [second]", got)
+	}
+	if n := b.dropped.Load(); n != 0 {
+		t.Fatalf("dropped = %d, want 0", n)
+	}
+}
+
+func TestEntryBufferBlockTimesOut(t *testing.T) {
+	b := newEntryBuffer(1, 0, OverflowBlock, 20*time.Millisecond, nil, nil)
+	b.add(LogEntry{Content: "first"}, 0)
+
+	start := time.Now()
+	if _, ok := b.add(LogEntry{Content: "second"}, 0); ok {
+		t.Fatal("add = true, want false after BlockTimeout")
+	}
+	if elapsed := time.Since(start); elapsed < 20*time.Millisecond {
+		t.Fatalf("add returned after %v, want to wait for BlockTimeout", elapsed)
+	}
+	if n := b.dropped.Load(); n != 1 {
+		t.Fatalf("dropped = %d, want 1", n)
+	}
+}
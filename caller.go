@@ -0,0 +1,93 @@
+package writer
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// callerPackages 记录调用位置时跳过的包（函数名前缀），默认为本库、logx 适配器和 Go 运行时
+var (
+	callerPackages   atomic.Pointer[[]string]
+	callerPackagesMu sync.Mutex
+)
+
+func init() {
+	pkg := reflect.TypeOf(LogField{}).PkgPath()
+	callerPackages.Store(&[]string{pkg + ".", pkg + "/logx.", "runtime."})
+}
+
+// SkipCallerPackage 将 pkg 视为日志包装层，记录调用位置时跳过其中的函数（如自定义的日志封装包），应在初始化阶段调用
+func SkipCallerPackage(pkg string) {
+	callerPackagesMu.Lock()
+	defer callerPackagesMu.Unlock()
+	old := *callerPackages.Load()
+	prefixes := make([]string, len(old), len(old)+1)
+	copy(prefixes, old)
+	prefixes = append(prefixes, pkg+".")
+	callerPackages.Store(&prefixes)
+}
+
+// FindCaller 返回调用位置（dir/file.go:line），跳过本库和 SkipCallerPackage 注册的包，
+// 再额外跳过 skip 个栈帧（用于自定义的日志封装函数）
+func FindCaller(skip int) string {
+	var pcs [32]uintptr
+	n := runtime.Callers(2, pcs[:])
+	frames := runtime.CallersFrames(pcs[:n])
+	prefixes := *callerPackages.Load()
+	for {
+		frame, more := frames.Next()
+		if !isWrapperFrame(frame.Function, prefixes) {
+			if skip <= 0 {
+				return formatCaller(frame.File, frame.Line)
+			}
+			skip--
+		}
+		if !more {
+			return ""
+		}
+	}
+}
+
+// isWrapperFrame 判断函数是否属于需要跳过的包
+func isWrapperFrame(function string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(function, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// formatCaller 格式化调用位置，保留文件所在目录，与 go-zero 的 caller 格式一致
+func formatCaller(file string, line int) string {
+	if i := strings.LastIndexByte(file, '/'); i >= 0 {
+		if j := strings.LastIndexByte(file[:i], '/'); j >= 0 {
+			file = file[j+1:]
+		}
+	}
+	return fmt.Sprintf("%s:%d", file, line)
+}
+
+// popCaller 从字段中取出 caller 字段（如 go-zero 或异步 MultiWriter 传入的调用位置）
+func popCaller(fields map[string]interface{}) string {
+	value, ok := fields["caller"]
+	if !ok {
+		return ""
+	}
+	delete(fields, "caller")
+	return FormatContent(value)
+}
+
+// hasField 判断 fields 中是否包含 key
+func hasField(fields []LogField, key string) bool {
+	for _, f := range fields {
+		if f.Key == key {
+			return true
+		}
+	}
+	return false
+}
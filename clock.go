@@ -0,0 +1,94 @@
+package writer
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Clock 时钟接口，写入器通过它获取日志时间，测试中可注入固定时钟
+type Clock interface {
+	Now() time.Time
+}
+
+// ClockFunc 将普通函数适配为 Clock
+type ClockFunc func() time.Time
+
+// Now 实现 Clock 接口
+func (f ClockFunc) Now() time.Time {
+	return f()
+}
+
+// SystemClock 系统时钟
+var SystemClock Clock = ClockFunc(time.Now)
+
+// entrySeq 进程内全局的日志序号，所有写入器共享，各写入器分配的序号在进程内唯一且单调递增；
+// 各子写入器各自分配序号时同一条日志的序号不同，需要一致时在 MultiConfig 中启用 Sequence，由 MultiWriter 统一分配
+var entrySeq atomic.Uint64
+
+// stamper 为日志条目生成时间戳和序号
+type stamper struct {
+	clock     Clock
+	utc       bool
+	precision time.Duration
+	sequence  bool
+}
+
+// defaultStamper 系统时钟、本地时区、纳秒精度、不分配序号
+var defaultStamper = newStamper(nil, false, 0, false)
+
+// newStamper 创建 stamper，clock 为 nil 时使用系统时钟
+func newStamper(clock Clock, utc bool, precision time.Duration, sequence bool) *stamper {
+	if clock == nil {
+		clock = SystemClock
+	}
+	return &stamper{clock: clock, utc: utc, precision: precision, sequence: sequence}
+}
+
+// mark 记录条目的时间（尚未记录时）并分配序号（启用且尚未分配时），不格式化时间戳，
+// 由各写入器按自己的时区和精度格式化
+func (s *stamper) mark(entry *LogEntry) {
+	if entry.Timestamp == "" && entry.time.IsZero() {
+		entry.time = s.clock.Now()
+	}
+	if s.sequence && entry.Seq == 0 {
+		entry.Seq = entrySeq.Add(1)
+	}
+}
+
+// stamp 为没有时间戳的条目格式化时间戳，并按配置分配序号
+func (s *stamper) stamp(entry *LogEntry) {
+	s.mark(entry)
+	if entry.Timestamp == "" {
+		entry.Timestamp = s.format(entry.time)
+	}
+}
+
+// format 按时区和精度格式化时间戳，小数位数固定，同一时区下字符串顺序与时间顺序一致
+func (s *stamper) format(t time.Time) string {
+	if s.utc {
+		t = t.UTC()
+	}
+	if s.precision > 0 {
+		t = t.Truncate(s.precision)
+	}
+	switch {
+	case s.precision >= time.Second:
+		return t.Format(time.RFC3339)
+	case s.precision >= time.Millisecond:
+		return t.Format("2006-01-02T15:04:05.000Z07:00")
+	case s.precision >= time.Microsecond:
+		return t.Format("2006-01-02T15:04:05.000000Z07:00")
+	default:
+		return t.Format("2006-01-02T15:04:05.000000000Z07:00")
+	}
+}
+
+// Time 返回条目的时间：已格式化时解析 Timestamp，否则返回构建条目时记录的时间
+func (e LogEntry) Time() time.Time {
+	if e.Timestamp != "" {
+		if t, err := time.Parse(time.RFC3339Nano, e.Timestamp); err == nil {
+			return t
+		}
+	}
+	return e.time
+}
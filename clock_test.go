@@ -0,0 +1,126 @@
+package writer
+
+import (
+	"testing"
+	"time"
+)
+
+// newClockedWriter 创建使用 clock 和序号、写入 sink 的 BatchWriter
+func newClockedWriter(t *testing.T, sink Sink, clock Clock, utc bool, precision time.Duration) *BatchWriter {
+	t.Helper()
+	config := testBatchConfig()
+	config.Clock = clock
+	config.UTC = utc
+	config.TimestampPrecision = precision
+	config.Sequence = true
+	w, err := NewBatchWriter(sink, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return w
+}
+
+func TestFixedClockTimestamp(t *testing.T) {
+	now := time.Date(2024, 1, 2, 3, 4, 5, 123456789, time.FixedZone("CST", 8*3600))
+	tests := []struct {
+		name      string
+		utc       bool
+		precision time.Duration
+		want      string
+	}{
+		{"local nanoseconds", false, 0, "2024-01-02T03:04:05.123456789+08:00"},
+		{"utc nanoseconds", true, 0, "2024-01-01T19:04:05.123456789Z"},
+		{"utc microseconds", true, time.Microsecond, "2024-01-01T19:04:05.123456Z"},
+		{"utc milliseconds", true, time.Millisecond, "2024-01-01T19:04:05.123Z"},
+		{"utc seconds", true, time.Second, "2024-01-01T19:04:05Z"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sink := &testSink{}
+			w := newClockedWriter(t, sink, fixedClock(now), tt.utc, tt.precision)
+			w.Info("message")
+			if err := w.Close(); err != nil {
+				t.Fatal(err)
+			}
+			entry := sink.written[0]
+			if entry.Timestamp != tt.want {
+				t.Fatalf("Timestamp = %q, want %q", entry.Timestamp, tt.want)
+			}
+			if !entry.Time().Equal(now.Truncate(tt.precision)) {
+				t.Fatalf("Time() = %v, want %v", entry.Time(), now)
+			}
+		})
+	}
+}
+
+// assertIncreasing 检查序号严格递增
+func assertIncreasing(t *testing.T, name string, entries []LogEntry) {
+	t.Helper()
+	for i, entry := range entries {
+		if entry.Seq == 0 {
+			t.Fatalf("%s[%d].Seq = 0, want an assigned sequence", name, i)
+		}
+		if i > 0 && entry.Seq <= entries[i-1].Seq {
+			t.Fatalf("%s[%d].Seq = %d, want greater than %d", name, i, entry.Seq, entries[i-1].Seq)
+		}
+	}
+}
+
+func TestSequenceIncreasesAcrossWithChildren(t *testing.T) {
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	sink := &testSink{}
+	w := newClockedWriter(t, sink, fixedClock(now), true, 0)
+
+	// 时钟固定时同一时刻的日志只能通过序号排序
+	a := With(w, Field("child", "a"))
+	b := With(w, Field("child", "b"))
+	for i := 0; i < 5; i++ {
+		a.Info("message")
+		b.Info("message")
+		w.Info("message")
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if n := sink.count(); n != 15 {
+		t.Fatalf("written = %d, want 15", n)
+	}
+	for i, entry := range sink.written {
+		if entry.Timestamp != "2024-01-02T03:04:05.000000000Z" {
+			t.Fatalf("written[%d].Timestamp = %q, want the fixed time", i, entry.Timestamp)
+		}
+	}
+	assertIncreasing(t, "written", sink.written)
+}
+
+func TestSequenceAcrossMultiWriterChildren(t *testing.T) {
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	for _, shared := range []bool{false, true} {
+		sinkA, sinkB := &testSink{}, &testSink{}
+		childA := newClockedWriter(t, sinkA, fixedClock(now), true, 0)
+		childB := newClockedWriter(t, sinkB, fixedClock(now), false, time.Second)
+		m := NewMultiWriterWithConfig(&MultiConfig{Clock: fixedClock(now), Sequence: shared}, childA, childB)
+		for i := 0; i < 5; i++ {
+			With(m, Field("i", i)).Info("message")
+		}
+		if err := m.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		assertIncreasing(t, "a", sinkA.written)
+		assertIncreasing(t, "b", sinkB.written)
+		for i := range sinkA.written {
+			a, b := sinkA.written[i], sinkB.written[i]
+			if !a.Time().Equal(b.Time()) {
+				t.Fatalf("entry %d time differs between children: %v and %v", i, a.Time(), b.Time())
+			}
+			if shared && a.Seq != b.Seq {
+				t.Fatalf("entry %d Seq = %d and %d, want equal with MultiConfig.Sequence", i, a.Seq, b.Seq)
+			}
+		}
+		if a, b := sinkA.written[0].Timestamp, sinkB.written[0].Timestamp; a != "2024-01-02T03:04:05.000000000Z" || b != now.Local().Format(time.RFC3339) {
+			t.Fatalf("Timestamps = %q and %q, want each child's own format", a, b)
+		}
+	}
+}
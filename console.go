@@ -1,52 +1,120 @@
 package writer
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"strings"
-	"time"
 )
 
+// ConsoleConfig 控制台 Writer 配置
+type ConsoleConfig struct {
+	MinLevel    Level        `json:"min_level,omitempty"` // 最低输出级别
+	AtomicLevel *AtomicLevel `json:"-"`                   // 可在运行时修改的最低级别，设置后忽略 MinLevel
+	Processors  []Processor  `json:"-"`                   // 输出前依次执行的处理器
+
+	DisableCaller bool `json:"disable_caller,omitempty"` // 不输出调用位置
+	CallerSkip    int  `json:"caller_skip,omitempty"`    // 获取调用位置时额外跳过的栈帧数
+
+	Clock Clock `json:"-"`             // 生成时间的时钟，nil 使用系统时钟
+	UTC   bool  `json:"utc,omitempty"` // 以 UTC 时区输出时间，默认使用本地时区
+}
+
 // ConsoleWriter 控制台 Writer，将日志输出到标准输出（不依赖 go-zero）
-type ConsoleWriter struct{}
+type ConsoleWriter struct {
+	level         *AtomicLevel
+	processors    []Processor
+	disableCaller bool
+	callerSkip    int
+	stamper       *stamper
+}
 
 // NewConsoleWriter 创建一个控制台 Writer
 func NewConsoleWriter() *ConsoleWriter {
-	return &ConsoleWriter{}
+	return &ConsoleWriter{level: NewAtomicLevel(LevelDebug), stamper: defaultStamper}
+}
+
+// NewConsoleWriterWithConfig 使用配置创建一个控制台 Writer
+func NewConsoleWriterWithConfig(config *ConsoleConfig) *ConsoleWriter {
+	if config == nil {
+		return NewConsoleWriter()
+	}
+	level := config.AtomicLevel
+	if level == nil {
+		level = NewAtomicLevel(config.MinLevel)
+	}
+	return &ConsoleWriter{
+		level:         level,
+		processors:    config.Processors,
+		disableCaller: config.DisableCaller,
+		callerSkip:    config.CallerSkip,
+		stamper:       newStamper(config.Clock, config.UTC, 0, false),
+	}
+}
+
+// AtomicLevel 返回写入器的最低级别，可用于运行时调整
+func (c *ConsoleWriter) AtomicLevel() *AtomicLevel {
+	return c.level
+}
+
+// log 内部日志方法
+func (c *ConsoleWriter) log(ctx context.Context, level string, content any, fields ...LogField) {
+	if c.level != nil && !c.level.Enabled(level) {
+		return
+	}
+	c.write(buildEntry(ctx, level, content, fields), fields)
+}
+
+// WriteEntry 输出已构建好的日志条目，实现 EntryWriter 接口
+func (c *ConsoleWriter) WriteEntry(entry LogEntry) {
+	if c.level != nil && !c.level.Enabled(entry.Level) {
+		return
+	}
+	c.write(entry, nil)
 }
 
-// log 内部日志方法，接收 caller 参数
-func (c *ConsoleWriter) log(level string, content any, caller string, fields ...LogField) {
-	timestamp := time.Now().Format("2006-01-02 15:04:05.000")
-	contentStr := FormatContent(content)
+// write 格式化并输出日志，fields 为调用时传入的字段，用于保持字段的输出顺序
+func (c *ConsoleWriter) write(entry LogEntry, fields []LogField) {
+	if entry.Caller == "" && !c.disableCaller {
+		entry.Caller = FindCaller(c.callerSkip)
+	}
+	if !ApplyProcessors(c.processors, &entry) {
+		return
+	}
+
+	c.stamper.mark(&entry)
+	t := entry.Time().Local()
+	if c.stamper.utc {
+		t = t.UTC()
+	}
+	timestamp := t.Format("2006-01-02 15:04:05.000")
 
 	var parts []string
-	parts = append(parts, fmt.Sprintf("[%s]", strings.ToUpper(level)))
+	parts = append(parts, fmt.Sprintf("[%s]", strings.ToUpper(entry.Level)))
 	parts = append(parts, timestamp)
-	if caller != "" {
-		parts = append(parts, caller)
+	if entry.Caller != "" {
+		parts = append(parts, entry.Caller)
 	}
-	parts = append(parts, contentStr)
+	parts = append(parts, entry.Content)
 
-	trace, span, duration := extractFields(fields)
-	if trace != "" {
-		parts = append(parts, fmt.Sprintf("trace=%s", trace))
+	if entry.Trace != "" {
+		parts = append(parts, fmt.Sprintf("trace=%s", entry.Trace))
 	}
-	if span != "" {
-		parts = append(parts, fmt.Sprintf("span=%s", span))
+	if entry.Span != "" {
+		parts = append(parts, fmt.Sprintf("span=%s", entry.Span))
 	}
-	if duration != "" {
-		parts = append(parts, fmt.Sprintf("duration=%s", duration))
+	if entry.Duration != "" {
+		parts = append(parts, fmt.Sprintf("duration=%s", entry.Duration))
 	}
 
-	for _, field := range fields {
-		if field.Key != "trace" && field.Key != "span" && field.Key != "duration" {
-			parts = append(parts, fmt.Sprintf("%s=%v", field.Key, field.Value))
+	for _, key := range fieldOrder(fields, entry.Fields) {
+		if key != "trace" && key != "span" && key != "duration" {
+			parts = append(parts, fmt.Sprintf("%s=%v", key, entry.Fields[key]))
 		}
 	}
 
 	output := strings.Join(parts, " ")
-	if level == "error" || level == "warn" {
+	if entry.Level == "error" || entry.Level == "warn" {
 		fmt.Fprintf(os.Stderr, "%s\n", output)
 	} else {
 		fmt.Fprintf(os.Stdout, "%s\n", output)
@@ -55,27 +123,52 @@ func (c *ConsoleWriter) log(level string, content any, caller string, fields ...
 
 // Log 写入日志（公开方法，供外部直接调用）
 func (c *ConsoleWriter) Log(level string, content any, fields ...LogField) {
-	c.log(level, content, GetCaller(2), fields...)
+	c.log(context.Background(), level, content, fields...)
 }
 
 // Info 写入 info 级别日志
 func (c *ConsoleWriter) Info(content any, fields ...LogField) {
-	c.log("info", content, GetCaller(2), fields...)
+	c.log(context.Background(), "info", content, fields...)
 }
 
 // Error 写入 error 级别日志
 func (c *ConsoleWriter) Error(content any, fields ...LogField) {
-	c.log("error", content, GetCaller(2), fields...)
+	c.log(context.Background(), "error", content, fields...)
 }
 
 // Debug 写入 debug 级别日志
 func (c *ConsoleWriter) Debug(content any, fields ...LogField) {
-	c.log("debug", content, GetCaller(2), fields...)
+	c.log(context.Background(), "debug", content, fields...)
 }
 
 // Warn 写入 warn 级别日志
 func (c *ConsoleWriter) Warn(content any, fields ...LogField) {
-	c.log("warn", content, GetCaller(2), fields...)
+	c.log(context.Background(), "warn", content, fields...)
+}
+
+// LogCtx 使用 ctx 写入日志
+func (c *ConsoleWriter) LogCtx(ctx context.Context, level string, content any, fields ...LogField) {
+	c.log(ctx, level, content, fields...)
+}
+
+// InfoCtx 使用 ctx 写入 info 级别日志
+func (c *ConsoleWriter) InfoCtx(ctx context.Context, content any, fields ...LogField) {
+	c.log(ctx, "info", content, fields...)
+}
+
+// ErrorCtx 使用 ctx 写入 error 级别日志
+func (c *ConsoleWriter) ErrorCtx(ctx context.Context, content any, fields ...LogField) {
+	c.log(ctx, "error", content, fields...)
+}
+
+// DebugCtx 使用 ctx 写入 debug 级别日志
+func (c *ConsoleWriter) DebugCtx(ctx context.Context, content any, fields ...LogField) {
+	c.log(ctx, "debug", content, fields...)
+}
+
+// WarnCtx 使用 ctx 写入 warn 级别日志
+func (c *ConsoleWriter) WarnCtx(ctx context.Context, content any, fields ...LogField) {
+	c.log(ctx, "warn", content, fields...)
 }
 
 // Close 关闭写入器（控制台 Writer 不需要关闭）
@@ -0,0 +1,68 @@
+package writer
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ContextWriter 支持 context 的 Writer，从 context 中读取 ContextWithFields 绑定的字段
+// 以及 OpenTelemetry 的 trace/span
+type ContextWriter interface {
+	LogCtx(ctx context.Context, level string, content any, fields ...LogField)
+	InfoCtx(ctx context.Context, content any, fields ...LogField)
+	ErrorCtx(ctx context.Context, content any, fields ...LogField)
+	DebugCtx(ctx context.Context, content any, fields ...LogField)
+	WarnCtx(ctx context.Context, content any, fields ...LogField)
+}
+
+// fieldsKey context 中绑定字段的键
+type fieldsKey struct{}
+
+// ContextWithFields 返回绑定了 fields 的 context，与 ctx 中已绑定的字段合并，同名字段以 fields 为准
+func ContextWithFields(ctx context.Context, fields ...LogField) context.Context {
+	return context.WithValue(ctx, fieldsKey{}, mergeFields(FieldsFromContext(ctx), fields))
+}
+
+// FieldsFromContext 返回 ctx 中绑定的字段
+func FieldsFromContext(ctx context.Context) []LogField {
+	fields, _ := ctx.Value(fieldsKey{}).([]LogField)
+	return fields
+}
+
+// TraceFromContext 返回 ctx 中当前 OpenTelemetry span 的 trace ID 和 span ID，没有有效 span 时返回空字符串
+func TraceFromContext(ctx context.Context) (traceID, spanID string) {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return "", ""
+	}
+	return sc.TraceID().String(), sc.SpanID().String()
+}
+
+// LogCtx 使用 ctx 写入日志：w 实现了 ContextWriter 时直接调用，否则将 context 中的字段和 trace/span
+// 作为普通字段传给 w.Log
+func LogCtx(ctx context.Context, w Writer, level string, content any, fields ...LogField) {
+	if cw, ok := w.(ContextWriter); ok {
+		cw.LogCtx(ctx, level, content, fields...)
+		return
+	}
+	w.Log(level, content, contextFields(ctx, fields)...)
+}
+
+// contextFields 合并 context 中绑定的字段和 fields，未显式传入 trace 时追加当前 span 的 trace/span 字段
+func contextFields(ctx context.Context, fields []LogField) []LogField {
+	merged := mergeFields(FieldsFromContext(ctx), fields)
+	traceID, spanID := TraceFromContext(ctx)
+	if traceID == "" || hasField(merged, "trace") {
+		return merged
+	}
+	return append(merged, Field("trace", traceID), Field("span", spanID))
+}
+
+// applyTraceContext 未通过字段指定 trace/span 时，使用 ctx 中当前 span 的 trace/span
+func applyTraceContext(ctx context.Context, entry *LogEntry) {
+	if entry.Trace != "" || entry.Span != "" {
+		return
+	}
+	entry.Trace, entry.Span = TraceFromContext(ctx)
+}
@@ -0,0 +1,128 @@
+package writer
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// DeadLetter 无法投递的日志记录，包含重放所需的全部信息
+type DeadLetter struct {
+	Entry    LogEntry  `json:"entry"`    // 原始日志条目
+	Target   string    `json:"target"`   // 目标索引名或表名
+	Error    string    `json:"error"`    // 最后一次失败的错误信息
+	Attempts int       `json:"attempts"` // 已尝试次数
+	Time     time.Time `json:"time"`     // 进入死信的时间
+}
+
+// DeadLetterSink 死信目的地，接收重试耗尽或被永久拒绝的日志
+type DeadLetterSink interface {
+	WriteDeadLetter(letter DeadLetter) error
+}
+
+// DeadLetterFunc 将普通函数适配为 DeadLetterSink
+type DeadLetterFunc func(letter DeadLetter) error
+
+// WriteDeadLetter 实现 DeadLetterSink 接口
+func (f DeadLetterFunc) WriteDeadLetter(letter DeadLetter) error {
+	return f(letter)
+}
+
+// FileDeadLetterSink 将死信以 NDJSON 格式追加写入本地文件
+type FileDeadLetterSink struct {
+	mu   sync.Mutex
+	file *os.File
+	enc  *json.Encoder
+}
+
+// NewFileDeadLetterSink 创建一个写入本地 NDJSON 文件的死信目的地
+func NewFileDeadLetterSink(path string) (*FileDeadLetterSink, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open dead letter file: %w", err)
+	}
+	return &FileDeadLetterSink{
+		file: file,
+		enc:  json.NewEncoder(file),
+	}, nil
+}
+
+// WriteDeadLetter 实现 DeadLetterSink 接口
+func (s *FileDeadLetterSink) WriteDeadLetter(letter DeadLetter) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.enc.Encode(letter); err != nil {
+		return fmt.Errorf("failed to write dead letter: %w", err)
+	}
+	return nil
+}
+
+// Close 关闭死信文件
+func (s *FileDeadLetterSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.file.Close()
+}
+
+// WriterDeadLetterSink 将死信转发到另一个 Writer，死信信息以 dead_letter_* 字段附加
+type WriterDeadLetterSink struct {
+	writer Writer
+}
+
+// NewWriterDeadLetterSink 创建一个转发到 Writer 的死信目的地
+func NewWriterDeadLetterSink(w Writer) *WriterDeadLetterSink {
+	return &WriterDeadLetterSink{writer: w}
+}
+
+// WriteDeadLetter 实现 DeadLetterSink 接口
+func (s *WriterDeadLetterSink) WriteDeadLetter(letter DeadLetter) error {
+	entry := letter.Entry
+	fields := make([]LogField, 0, len(entry.Fields)+7)
+	for key, value := range entry.Fields {
+		fields = append(fields, Field(key, value))
+	}
+	if entry.Trace != "" {
+		fields = append(fields, Field("trace", entry.Trace))
+	}
+	if entry.Span != "" {
+		fields = append(fields, Field("span", entry.Span))
+	}
+	if entry.Duration != "" {
+		fields = append(fields, Field("duration", entry.Duration))
+	}
+	fields = append(fields,
+		Field("dead_letter_target", letter.Target),
+		Field("dead_letter_error", letter.Error),
+		Field("dead_letter_attempts", letter.Attempts),
+		Field("dead_letter_timestamp", entry.Timestamp),
+	)
+	s.writer.Log(entry.Level, entry.Content, fields...)
+	return nil
+}
+
+// sendDeadLetters 将一批无法投递的日志交给死信目的地，返回写入死信失败的条目数和最后一个错误
+func sendDeadLetters(sink DeadLetterSink, entries []LogEntry, target string, err error, attempts int) (failed int, lastErr error) {
+	if sink == nil || len(entries) == 0 {
+		return 0, nil
+	}
+	msg := ""
+	if err != nil {
+		msg = err.Error()
+	}
+	now := time.Now()
+	for _, entry := range entries {
+		if werr := sink.WriteDeadLetter(DeadLetter{
+			Entry:    entry,
+			Target:   target,
+			Error:    msg,
+			Attempts: attempts,
+			Time:     now,
+		}); werr != nil {
+			failed++
+			lastErr = werr
+		}
+	}
+	return failed, lastErr
+}
@@ -0,0 +1,75 @@
+package writer
+
+import (
+	"context"
+	"sort"
+)
+
+// EntryWriter 接收已构建好的 LogEntry 的 Writer，MultiWriter 等包装层只构建一次条目，
+// 将同一条目（相同的时间、序号、调用位置和 trace/span）交给每个子 Writer。
+// 实现不应修改 entry.Fields 和 entry.Metadata 指向的 map，ApplyProcessors 会在执行处理器前复制 Fields
+type EntryWriter interface {
+	WriteEntry(entry LogEntry)
+}
+
+// NewEntry 构建日志条目：记录当前时间，合并 ctx 中绑定的字段，提取 trace/span/duration/caller 特殊字段，
+// 未指定 trace/span 时使用 ctx 中的当前 span；时间戳由写入器按各自的时区和精度格式化，
+// 调用位置由写入器在条目没有 Caller 时获取
+func NewEntry(ctx context.Context, level string, content any, fields ...LogField) LogEntry {
+	entry := buildEntry(ctx, level, content, fields)
+	defaultStamper.mark(&entry)
+	return entry
+}
+
+// buildEntry 构建不含时间的日志条目，时间由写入器的时钟记录
+func buildEntry(ctx context.Context, level string, content any, fields []LogField) LogEntry {
+	if bound := FieldsFromContext(ctx); len(bound) > 0 {
+		fields = mergeFields(bound, fields)
+	}
+	trace, span, duration := extractFields(fields)
+	entry := LogEntry{
+		Level:    level,
+		Content:  FormatContent(content),
+		Duration: duration,
+		Trace:    trace,
+		Span:     span,
+		Fields:   convertFields(fields),
+	}
+	entry.Caller = popCaller(entry.Fields)
+	applyTraceContext(ctx, &entry)
+	return entry
+}
+
+// WriteEntry 将条目写入 w：w 实现了 EntryWriter 时直接调用，否则将条目还原为字段传给 w.Log
+func WriteEntry(w Writer, entry LogEntry) {
+	if ew, ok := w.(EntryWriter); ok {
+		ew.WriteEntry(entry)
+		return
+	}
+	w.Log(entry.Level, entry.Content, entryFields(entry)...)
+}
+
+// entryFields 将条目的字段还原为 LogField（按字段名排序），trace/span/duration/caller 作为特殊字段追加
+func entryFields(entry LogEntry) []LogField {
+	keys := make([]string, 0, len(entry.Fields))
+	for key := range entry.Fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	fields := make([]LogField, 0, len(keys)+4)
+	for _, key := range keys {
+		fields = append(fields, Field(key, entry.Fields[key]))
+	}
+	for _, special := range []LogField{
+		{Key: "trace", Value: entry.Trace},
+		{Key: "span", Value: entry.Span},
+		{Key: "duration", Value: entry.Duration},
+		{Key: "caller", Value: entry.Caller},
+	} {
+		if special.Value != "" && !hasField(fields, special.Key) {
+			fields = append(fields, special)
+		}
+	}
+	return fields
+}
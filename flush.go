@@ -0,0 +1,150 @@
+package writer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// Flusher 支持主动刷新的写入器
+type Flusher interface {
+	Flush(ctx context.Context) error
+}
+
+// ContextCloser 支持截止时间的关闭
+type ContextCloser interface {
+	CloseContext(ctx context.Context) error
+}
+
+// UnsentError 截止时间到达时仍有日志未能发送
+type UnsentError struct {
+	Unsent int   // 未发送的条目数
+	Err    error // 导致中止的错误，通常为 context.DeadlineExceeded
+}
+
+func (e *UnsentError) Error() string {
+	return fmt.Sprintf("%d log entries left unsent: %v", e.Unsent, e.Err)
+}
+
+func (e *UnsentError) Unwrap() error {
+	return e.Err
+}
+
+// runAll 并发地对每个写入器执行 fn，汇总未发送条目数和其他错误
+func runAll(ctx context.Context, writers []Writer, action string, fn func(w Writer) error) error {
+	errs := make([]error, len(writers))
+	var wg sync.WaitGroup
+	for i, w := range writers {
+		wg.Add(1)
+		go func(i int, w Writer) {
+			defer wg.Done()
+			errs[i] = fn(w)
+		}(i, w)
+	}
+	wg.Wait()
+
+	unsent := 0
+	var others []error
+	for _, err := range errs {
+		if err == nil {
+			continue
+		}
+		var ue *UnsentError
+		if errors.As(err, &ue) {
+			unsent += ue.Unsent
+			continue
+		}
+		others = append(others, err)
+	}
+	if unsent > 0 {
+		return &UnsentError{Unsent: unsent, Err: ctx.Err()}
+	}
+	if len(others) > 0 {
+		return fmt.Errorf("errors %s writers: %v", action, others)
+	}
+	return nil
+}
+
+// sealedBatch 交给发送 worker 的一批日志
+type sealedBatch struct {
+	seq   uint64
+	items []bufferedEntry
+}
+
+// batchTracker 跟踪在途批次，用于 Flush 等待此前封装的批次发送完成
+type batchTracker struct {
+	mu      sync.Mutex
+	lastSeq uint64
+	pending map[uint64]int // seq -> 条目数
+	changed chan struct{}  // 有批次完成时关闭
+}
+
+// newBatchTracker 创建批次跟踪器
+func newBatchTracker() *batchTracker {
+	return &batchTracker{
+		pending: make(map[uint64]int),
+		changed: make(chan struct{}),
+	}
+}
+
+// start 登记一个新批次，返回其序号
+func (t *batchTracker) start(entries int) uint64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.lastSeq++
+	t.pending[t.lastSeq] = entries
+	return t.lastSeq
+}
+
+// finish 标记批次已处理完成
+func (t *batchTracker) finish(seq uint64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.pending, seq)
+	close(t.changed)
+	t.changed = make(chan struct{})
+}
+
+// last 返回最近登记的批次序号
+func (t *batchTracker) last() uint64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.lastSeq
+}
+
+// entries 返回在途批次中的条目总数
+func (t *batchTracker) entries() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	n := 0
+	for _, c := range t.pending {
+		n += c
+	}
+	return n
+}
+
+// wait 等待序号不大于 upTo 的批次全部完成
+func (t *batchTracker) wait(ctx context.Context, upTo uint64) error {
+	for {
+		t.mu.Lock()
+		done := true
+		for seq := range t.pending {
+			if seq <= upTo {
+				done = false
+				break
+			}
+		}
+		changed := t.changed
+		t.mu.Unlock()
+
+		if done {
+			return nil
+		}
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,293 @@
+package writer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Level 日志级别，按 debug < info < warn < error 排序，零值为 LevelDebug（不过滤）
+type Level int8
+
+const (
+	// LevelDebug 调试日志
+	LevelDebug Level = iota
+	// LevelInfo 普通日志，go-zero 的 stat 归入此级别
+	LevelInfo
+	// LevelWarn 警告日志，go-zero 的 slow 归入此级别
+	LevelWarn
+	// LevelError 错误日志，go-zero 的 severe/alert/stack 归入此级别
+	LevelError
+)
+
+// String 返回级别名称
+func (l Level) String() string {
+	switch l {
+	case LevelDebug:
+		return "debug"
+	case LevelInfo:
+		return "info"
+	case LevelWarn:
+		return "warn"
+	case LevelError:
+		return "error"
+	default:
+		return fmt.Sprintf("level(%d)", int8(l))
+	}
+}
+
+// MarshalText 实现 encoding.TextMarshaler，配置中以字符串表示级别
+func (l Level) MarshalText() ([]byte, error) {
+	return []byte(l.String()), nil
+}
+
+// UnmarshalText 实现 encoding.TextUnmarshaler，空字符串表示 LevelDebug
+func (l *Level) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*l = LevelDebug
+		return nil
+	}
+	level, err := ParseLevel(string(text))
+	if err != nil {
+		return err
+	}
+	*l = level
+	return nil
+}
+
+// Enabled 判断 level 级别的日志是否应被写入
+func (l Level) Enabled(level string) bool {
+	return LevelOf(level) >= l
+}
+
+// ParseLevel 解析级别名称（不区分大小写），go-zero 的 slow/stat/severe/alert/stack 会映射到对应级别
+func ParseLevel(s string) (Level, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "debug":
+		return LevelDebug, nil
+	case "info", "stat":
+		return LevelInfo, nil
+	case "warn", "warning", "slow":
+		return LevelWarn, nil
+	case "error", "severe", "alert", "stack", "fatal":
+		return LevelError, nil
+	default:
+		return LevelDebug, fmt.Errorf("unknown log level: %q", s)
+	}
+}
+
+// LevelOf 返回日志条目级别对应的 Level，无法识别的级别视为 LevelInfo
+func LevelOf(level string) Level {
+	l, err := ParseLevel(level)
+	if err != nil {
+		return LevelInfo
+	}
+	return l
+}
+
+// AtomicLevel 可在运行时并发修改的最低级别，Writer 和 LevelFilter 每次写入时读取
+type AtomicLevel struct {
+	level atomic.Int32
+
+	mu       sync.Mutex
+	base     Level       // 临时级别到期后恢复的级别
+	timer    *time.Timer // 临时级别的恢复定时器
+	revertAt time.Time
+}
+
+// NewAtomicLevel 创建一个初始级别为 level 的 AtomicLevel
+func NewAtomicLevel(level Level) *AtomicLevel {
+	a := &AtomicLevel{base: level}
+	a.level.Store(int32(level))
+	return a
+}
+
+// Level 返回当前级别
+func (a *AtomicLevel) Level() Level {
+	return Level(a.level.Load())
+}
+
+// Enabled 判断 level 级别的日志是否应被写入
+func (a *AtomicLevel) Enabled(level string) bool {
+	return a.Level().Enabled(level)
+}
+
+// SetLevel 永久修改级别，并取消尚未到期的临时级别
+func (a *AtomicLevel) SetLevel(level Level) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.stopTimer()
+	a.base = level
+	a.level.Store(int32(level))
+}
+
+// SetLevelFor 临时修改级别，ttl 到期后恢复为修改前的永久级别；ttl <= 0 时等同于 SetLevel
+func (a *AtomicLevel) SetLevelFor(level Level, ttl time.Duration) {
+	if ttl <= 0 {
+		a.SetLevel(level)
+		return
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.stopTimer()
+	a.level.Store(int32(level))
+	a.revertAt = time.Now().Add(ttl)
+	var timer *time.Timer
+	timer = time.AfterFunc(ttl, func() {
+		a.mu.Lock()
+		defer a.mu.Unlock()
+		if a.timer != timer {
+			return
+		}
+		a.timer = nil
+		a.revertAt = time.Time{}
+		a.level.Store(int32(a.base))
+	})
+	a.timer = timer
+}
+
+// RevertAt 返回临时级别的到期时间，没有临时级别时返回零值
+func (a *AtomicLevel) RevertAt() time.Time {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.revertAt
+}
+
+// Base 返回临时级别到期后恢复的级别
+func (a *AtomicLevel) Base() Level {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.base
+}
+
+// stopTimer 取消恢复定时器，调用时需持有锁
+func (a *AtomicLevel) stopTimer() {
+	if a.timer != nil {
+		a.timer.Stop()
+		a.timer = nil
+	}
+	a.revertAt = time.Time{}
+}
+
+// LevelFilter 按最低级别过滤日志的 Writer 包装器
+type LevelFilter struct {
+	writer Writer
+	level  *AtomicLevel
+}
+
+// NewLevelFilter 创建一个只写入不低于 min 级别日志的 Writer
+func NewLevelFilter(w Writer, min Level) *LevelFilter {
+	return NewAtomicLevelFilter(w, NewAtomicLevel(min))
+}
+
+// NewAtomicLevelFilter 创建一个按 level 当前值过滤日志的 Writer，level 可在运行时修改
+func NewAtomicLevelFilter(w Writer, level *AtomicLevel) *LevelFilter {
+	return &LevelFilter{writer: w, level: level}
+}
+
+// AtomicLevel 返回过滤使用的级别，可用于运行时调整
+func (f *LevelFilter) AtomicLevel() *AtomicLevel {
+	return f.level
+}
+
+// Log 写入日志
+func (f *LevelFilter) Log(level string, content any, fields ...LogField) {
+	if f.level.Enabled(level) {
+		f.writer.Log(level, content, fields...)
+	}
+}
+
+// Info 写入 info 级别日志
+func (f *LevelFilter) Info(content any, fields ...LogField) {
+	if f.level.Level() <= LevelInfo {
+		f.writer.Info(content, fields...)
+	}
+}
+
+// Error 写入 error 级别日志
+func (f *LevelFilter) Error(content any, fields ...LogField) {
+	if f.level.Level() <= LevelError {
+		f.writer.Error(content, fields...)
+	}
+}
+
+// Debug 写入 debug 级别日志
+func (f *LevelFilter) Debug(content any, fields ...LogField) {
+	if f.level.Level() <= LevelDebug {
+		f.writer.Debug(content, fields...)
+	}
+}
+
+// Warn 写入 warn 级别日志
+func (f *LevelFilter) Warn(content any, fields ...LogField) {
+	if f.level.Level() <= LevelWarn {
+		f.writer.Warn(content, fields...)
+	}
+}
+
+// LogCtx 使用 ctx 写入日志
+func (f *LevelFilter) LogCtx(ctx context.Context, level string, content any, fields ...LogField) {
+	if f.level.Enabled(level) {
+		LogCtx(ctx, f.writer, level, content, fields...)
+	}
+}
+
+// InfoCtx 使用 ctx 写入 info 级别日志
+func (f *LevelFilter) InfoCtx(ctx context.Context, content any, fields ...LogField) {
+	f.LogCtx(ctx, "info", content, fields...)
+}
+
+// ErrorCtx 使用 ctx 写入 error 级别日志
+func (f *LevelFilter) ErrorCtx(ctx context.Context, content any, fields ...LogField) {
+	f.LogCtx(ctx, "error", content, fields...)
+}
+
+// DebugCtx 使用 ctx 写入 debug 级别日志
+func (f *LevelFilter) DebugCtx(ctx context.Context, content any, fields ...LogField) {
+	f.LogCtx(ctx, "debug", content, fields...)
+}
+
+// WarnCtx 使用 ctx 写入 warn 级别日志
+func (f *LevelFilter) WarnCtx(ctx context.Context, content any, fields ...LogField) {
+	f.LogCtx(ctx, "warn", content, fields...)
+}
+
+// WriteEntry 写入已构建好的日志条目，实现 EntryWriter 接口
+func (f *LevelFilter) WriteEntry(entry LogEntry) {
+	if f.level.Enabled(entry.Level) {
+		WriteEntry(f.writer, entry)
+	}
+}
+
+// Close 关闭被包装的 Writer
+func (f *LevelFilter) Close() error {
+	return f.writer.Close()
+}
+
+// Flush 刷新被包装的 Writer（如果支持）
+func (f *LevelFilter) Flush(ctx context.Context) error {
+	if fl, ok := f.writer.(Flusher); ok {
+		return fl.Flush(ctx)
+	}
+	return nil
+}
+
+// CloseContext 带截止时间关闭被包装的 Writer
+func (f *LevelFilter) CloseContext(ctx context.Context) error {
+	if c, ok := f.writer.(ContextCloser); ok {
+		return c.CloseContext(ctx)
+	}
+	return f.writer.Close()
+}
+
+// Stats 返回被包装 Writer 的统计（如果支持）
+func (f *LevelFilter) Stats() Stats {
+	if p, ok := f.writer.(StatsProvider); ok {
+		return p.Stats()
+	}
+	return Stats{}
+}
@@ -5,7 +5,6 @@ package logx
 import (
 	"context"
 	"runtime"
-	"time"
 
 	"github.com/zeromicro/go-zero/core/logx"
 	writer "github.com/zhengliu92/es-log-writer"
@@ -25,26 +24,27 @@ func NewEsAdapter(config *writer.Config) (*Adapter, error) {
 	return &Adapter{ElasticsearchWriter: w}, nil
 }
 
-// createLogEntry 创建日志条目（辅助函数，从 fields 中提取 caller）
+// createLogEntry 创建日志条目（辅助函数，从 fields 中提取 caller，时间戳由 AddEntry 按写入器配置生成）
 func createLogEntry(level string, content any, fields ...logx.LogField) writer.LogEntry {
 	trace, span, duration := extractLogxFields(fields...)
-	return writer.LogEntry{
-		Timestamp: time.Now().Format(time.RFC3339),
-		Level:     level,
-		Content:   writer.FormatContent(content),
-		Duration:  duration,
-		Trace:     trace,
-		Span:      span,
-		Fields:    convertLogxFields(fields...),
+	entry := writer.LogEntry{
+		Level:    level,
+		Content:  writer.FormatContent(content),
+		Duration: duration,
+		Trace:    trace,
+		Span:     span,
+		Caller:   extractCaller(fields...),
+		Fields:   convertLogxFields(fields...),
 	}
+	delete(entry.Fields, "caller")
+	return entry
 }
 
-// createSimpleLogEntry 创建简单日志条目（无字段，需要手动获取 caller）
+// createSimpleLogEntry 创建简单日志条目（无字段，caller 由 AddEntry 从调用栈获取）
 func createSimpleLogEntry(level string, content any) writer.LogEntry {
 	return writer.LogEntry{
-		Timestamp: time.Now().Format(time.RFC3339),
-		Level:     level,
-		Content:   writer.FormatContent(content),
+		Level:   level,
+		Content: writer.FormatContent(content),
 	}
 }
 
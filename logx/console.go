@@ -11,13 +11,20 @@ import (
 )
 
 // ConsoleWriter 控制台 Writer，将日志输出到标准输出
-type ConsoleWriter struct{}
+type ConsoleWriter struct {
+	processors []writer.Processor
+}
 
 // NewConsoleWriter 创建一个控制台 Writer
 func NewConsoleWriter() *ConsoleWriter {
 	return &ConsoleWriter{}
 }
 
+// NewConsoleWriterWithProcessors 创建一个输出前依次执行 processors 的控制台 Writer
+func NewConsoleWriterWithProcessors(processors ...writer.Processor) *ConsoleWriter {
+	return &ConsoleWriter{processors: processors}
+}
+
 // Alert 实现 logx.Writer 接口
 func (c *ConsoleWriter) Alert(v any) {
 	c.log("alert", v)
@@ -65,21 +72,29 @@ func (c *ConsoleWriter) Close() error {
 
 // log 辅助方法，格式化并输出日志
 func (c *ConsoleWriter) log(level string, v any, fields ...logx.LogField) {
+	entry := writer.LogEntry{
+		Level:   level,
+		Content: writer.FormatContent(v),
+		Fields:  convertLogxFields(fields...),
+	}
+	if !writer.ApplyProcessors(c.processors, &entry) {
+		return
+	}
+
 	timestamp := time.Now().Format("2006-01-02 15:04:05.000")
-	content := writer.FormatContent(v)
 
 	var parts []string
-	parts = append(parts, fmt.Sprintf("[%s]", strings.ToUpper(level)))
+	parts = append(parts, fmt.Sprintf("[%s]", strings.ToUpper(entry.Level)))
 	parts = append(parts, timestamp)
-	parts = append(parts, content)
+	parts = append(parts, entry.Content)
 
 	// 输出其他字段
-	for _, field := range fields {
-		parts = append(parts, fmt.Sprintf("%s=%v", field.Key, field.Value))
+	for _, key := range writer.FieldOrder(adaptLogxFields(fields...), entry.Fields) {
+		parts = append(parts, fmt.Sprintf("%s=%v", key, entry.Fields[key]))
 	}
 
 	output := strings.Join(parts, " ")
-	if level == "alert" || level == "severe" || level == "stack" {
+	if entry.Level == "alert" || entry.Level == "severe" || entry.Level == "stack" {
 		fmt.Fprintf(os.Stderr, "%s\n", output)
 	} else {
 		fmt.Fprintf(os.Stdout, "%s\n", output)
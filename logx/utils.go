@@ -1,10 +1,17 @@
 package logx
 
 import (
+	"reflect"
+
 	"github.com/zeromicro/go-zero/core/logx"
 	writer "github.com/zhengliu92/es-log-writer"
 )
 
+func init() {
+	// go-zero 的 logx 包是日志包装层，记录调用位置时跳过
+	writer.SkipCallerPackage(reflect.TypeOf(logx.LogField{}).PkgPath())
+}
+
 // logxFieldAdapter 适配 logx.LogField 到 writer.FieldAccessor 接口
 type logxFieldAdapter struct {
 	field logx.LogField
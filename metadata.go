@@ -0,0 +1,141 @@
+package writer
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"runtime/debug"
+	"sort"
+)
+
+// reservedKeys LogEntry 内置的顶层字段，Metadata 不能使用这些名称
+var reservedKeys = map[string]bool{
+	"@timestamp": true,
+	"level":      true,
+	"content":    true,
+	"duration":   true,
+	"trace":      true,
+	"span":       true,
+	"caller":     true,
+	"seq":        true,
+	"fields":     true,
+}
+
+// logEntryJSON 用于 LogEntry 默认的 JSON 编解码，避免递归调用 MarshalJSON
+type logEntryJSON LogEntry
+
+// MarshalJSON 实现 json.Marshaler，Metadata 中的字段与内置字段平铺在同一层
+func (e LogEntry) MarshalJSON() ([]byte, error) {
+	data, err := json.Marshal(logEntryJSON(e))
+	if err != nil || len(e.Metadata) == 0 {
+		return data, err
+	}
+
+	keys := make([]string, 0, len(e.Metadata))
+	for key := range e.Metadata {
+		if !reservedKeys[key] {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	buf := data[:len(data)-1]
+	for _, key := range keys {
+		name, _ := json.Marshal(key)
+		value, err := json.Marshal(e.Metadata[key])
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal metadata %q: %w", key, err)
+		}
+		buf = append(buf, ',')
+		buf = append(buf, name...)
+		buf = append(buf, ':')
+		buf = append(buf, value...)
+	}
+	return append(buf, '}'), nil
+}
+
+// UnmarshalJSON 实现 json.Unmarshaler，内置字段以外的顶层字段解析到 Metadata
+func (e *LogEntry) UnmarshalJSON(data []byte) error {
+	var entry logEntryJSON
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return err
+	}
+	var all map[string]json.RawMessage
+	if err := json.Unmarshal(data, &all); err != nil {
+		return err
+	}
+	entry.Metadata = nil
+	for key, raw := range all {
+		if reservedKeys[key] {
+			continue
+		}
+		var value any
+		if err := json.Unmarshal(raw, &value); err != nil {
+			return err
+		}
+		if entry.Metadata == nil {
+			entry.Metadata = make(map[string]interface{})
+		}
+		entry.Metadata[key] = value
+	}
+	*e = LogEntry(entry)
+	return nil
+}
+
+// buildMetadata 合并服务信息、静态字段和运行时信息，StaticFields 中的同名字段优先
+func buildMetadata(service, environment, version string, static map[string]interface{}, runtimeInfo bool) (map[string]interface{}, error) {
+	metadata := make(map[string]interface{})
+	if runtimeInfo {
+		for key, value := range runtimeMetadata() {
+			metadata[key] = value
+		}
+	}
+	if service != "" {
+		metadata["service"] = service
+	}
+	if environment != "" {
+		metadata["env"] = environment
+	}
+	if version != "" {
+		metadata["version"] = version
+	}
+	for key, value := range static {
+		if reservedKeys[key] {
+			return nil, fmt.Errorf("static field %q conflicts with a built-in field", key)
+		}
+		metadata[key] = value
+	}
+	if len(metadata) == 0 {
+		return nil, nil
+	}
+	return metadata, nil
+}
+
+// runtimeMetadata 收集主机名、进程号和 Go 构建信息
+func runtimeMetadata() map[string]interface{} {
+	metadata := map[string]interface{}{
+		"pid": os.Getpid(),
+	}
+	if host, err := os.Hostname(); err == nil {
+		metadata["host"] = host
+	}
+	if info, ok := debug.ReadBuildInfo(); ok {
+		build := map[string]interface{}{
+			"go_version": info.GoVersion,
+			"path":       info.Main.Path,
+			"version":    info.Main.Version,
+		}
+		for _, setting := range info.Settings {
+			switch setting.Key {
+			case "vcs.revision":
+				build["revision"] = setting.Value
+			case "vcs.time":
+				build["commit_time"] = setting.Value
+			case "vcs.modified":
+				build["modified"] = setting.Value == "true"
+			}
+		}
+		metadata["build"] = build
+	}
+	return metadata
+}
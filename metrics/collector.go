@@ -0,0 +1,150 @@
+// Package metrics 提供写入器运行统计的 Prometheus 导出器
+// 使用此包需要依赖 prometheus/client_golang
+package metrics
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	writer "github.com/zhengliu92/es-log-writer"
+)
+
+// DefaultNamespace 默认的指标命名空间
+const DefaultNamespace = "log_writer"
+
+// Collector 将 writer.StatsProvider 的统计导出为 Prometheus 指标
+type Collector struct {
+	mu      sync.RWMutex
+	writers map[string]writer.StatsProvider
+
+	queueDepth       *prometheus.Desc
+	accepted         *prometheus.Desc
+	shipped          *prometheus.Desc
+	failed           *prometheus.Desc
+	dropped          *prometheus.Desc
+	retried          *prometheus.Desc
+	deadLetterErrors *prometheus.Desc
+	bytesSent        *prometheus.Desc
+	bytesRaw         *prometheus.Desc
+	flushes          *prometheus.Desc
+	flushDuration    *prometheus.Desc
+	lastFlush        *prometheus.Desc
+}
+
+// NewCollector 创建一个 Prometheus Collector，namespace 为空时使用 DefaultNamespace
+func NewCollector(namespace string) *Collector {
+	if namespace == "" {
+		namespace = DefaultNamespace
+	}
+	desc := func(name, help string, labels ...string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(namespace, "", name), help, labels, nil)
+	}
+	return &Collector{
+		writers: make(map[string]writer.StatsProvider),
+
+		queueDepth:       desc("queue_depth", "Number of log entries currently buffered.", "writer"),
+		accepted:         desc("entries_accepted_total", "Log entries accepted into the buffer.", "writer", "level"),
+		shipped:          desc("entries_shipped_total", "Log entries successfully written to the backend.", "writer", "level"),
+		failed:           desc("entries_failed_total", "Log entries permanently rejected or that exhausted retries.", "writer", "level"),
+		dropped:          desc("entries_dropped_total", "Log entries dropped because the buffer was full.", "writer", "level"),
+		retried:          desc("entries_retried_total", "Log entries re-sent after a failed attempt.", "writer"),
+		deadLetterErrors: desc("dead_letter_errors_total", "Log entries that could not be written to the dead letter sink.", "writer"),
+		bytesSent:        desc("bytes_sent_total", "Bytes sent to the backend after compression, including retries.", "writer"),
+		bytesRaw:         desc("bytes_raw_total", "Bytes sent to the backend before compression, including retries.", "writer"),
+		flushes:          desc("flushes_total", "Number of buffer flushes.", "writer"),
+		flushDuration:    desc("flush_duration_seconds", "Duration of buffer flushes, including retries.", "writer"),
+		lastFlush:        desc("last_flush_timestamp_seconds", "Unix time of the last completed flush.", "writer"),
+	}
+}
+
+// Add 注册一个写入器，name 作为指标的 writer 标签；同名写入器会被替换
+func (c *Collector) Add(name string, w writer.StatsProvider) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.writers[name] = w
+}
+
+// Remove 移除一个写入器
+func (c *Collector) Remove(name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.writers, name)
+}
+
+// Describe 实现 prometheus.Collector 接口
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.queueDepth
+	ch <- c.accepted
+	ch <- c.shipped
+	ch <- c.failed
+	ch <- c.dropped
+	ch <- c.retried
+	ch <- c.deadLetterErrors
+	ch <- c.bytesSent
+	ch <- c.bytesRaw
+	ch <- c.flushes
+	ch <- c.flushDuration
+	ch <- c.lastFlush
+}
+
+// Collect 实现 prometheus.Collector 接口
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.RLock()
+	names := make([]string, 0, len(c.writers))
+	for name := range c.writers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	providers := make([]writer.StatsProvider, len(names))
+	for i, name := range names {
+		providers[i] = c.writers[name]
+	}
+	c.mu.RUnlock()
+
+	for i, name := range names {
+		c.collectWriter(ch, name, providers[i].Stats())
+	}
+}
+
+// collectWriter 导出单个写入器的指标
+func (c *Collector) collectWriter(ch chan<- prometheus.Metric, name string, stats writer.Stats) {
+	ch <- prometheus.MustNewConstMetric(c.queueDepth, prometheus.GaugeValue, float64(stats.BufferDepth), name)
+	ch <- prometheus.MustNewConstMetric(c.retried, prometheus.CounterValue, float64(stats.Retried), name)
+	ch <- prometheus.MustNewConstMetric(c.deadLetterErrors, prometheus.CounterValue, float64(stats.DeadLetterErrors), name)
+	ch <- prometheus.MustNewConstMetric(c.bytesSent, prometheus.CounterValue, float64(stats.BytesSent), name)
+	ch <- prometheus.MustNewConstMetric(c.bytesRaw, prometheus.CounterValue, float64(stats.BytesRaw), name)
+	ch <- prometheus.MustNewConstMetric(c.flushes, prometheus.CounterValue, float64(stats.Flushes), name)
+	if !stats.LastFlushTime.IsZero() {
+		ch <- prometheus.MustNewConstMetric(c.lastFlush, prometheus.GaugeValue,
+			float64(stats.LastFlushTime.UnixNano())/1e9, name)
+	}
+
+	for level, ls := range stats.Levels {
+		ch <- prometheus.MustNewConstMetric(c.accepted, prometheus.CounterValue, float64(ls.Accepted), name, level)
+		ch <- prometheus.MustNewConstMetric(c.shipped, prometheus.CounterValue, float64(ls.Flushed), name, level)
+		ch <- prometheus.MustNewConstMetric(c.failed, prometheus.CounterValue, float64(ls.Failed), name, level)
+		ch <- prometheus.MustNewConstMetric(c.dropped, prometheus.CounterValue, float64(ls.Dropped), name, level)
+	}
+
+	buckets := make(map[float64]uint64, len(writer.FlushDurationBuckets))
+	for i, bound := range writer.FlushDurationBuckets {
+		if i < len(stats.FlushDuration.Buckets) {
+			buckets[bound] = stats.FlushDuration.Buckets[i]
+		}
+	}
+	ch <- prometheus.MustNewConstHistogram(c.flushDuration,
+		stats.FlushDuration.Count, stats.FlushDuration.Sum, buckets, name)
+}
+
+// Register 创建 Collector 并注册到 reg，writers 的 key 作为 writer 标签
+func Register(reg prometheus.Registerer, namespace string, writers map[string]writer.StatsProvider) (*Collector, error) {
+	c := NewCollector(namespace)
+	for name, w := range writers {
+		c.Add(name, w)
+	}
+	if err := reg.Register(c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
@@ -1,9 +1,15 @@
 package writer
 
 import (
+	"context"
 	"fmt"
+	"sync"
+	"sync/atomic"
 )
 
+// defaultMultiQueueSize 异步模式下每个子 Writer 的默认队列长度
+const defaultMultiQueueSize = 1024
+
 // Writer 日志写入器接口（不依赖 go-zero）
 type Writer interface {
 	Info(content any, fields ...LogField)
@@ -14,55 +20,177 @@ type Writer interface {
 	Close() error
 }
 
+// MultiConfig MultiWriter 配置
+type MultiConfig struct {
+	Async     bool `json:"async,omitempty"`      // 异步模式：每个子 Writer 使用独立的有界队列和 goroutine
+	QueueSize int  `json:"queue_size,omitempty"` // 异步模式下每个子 Writer 的队列长度，队列满时丢弃新日志
+
+	DisableCaller bool `json:"disable_caller,omitempty"` // 不记录调用位置
+	CallerSkip    int  `json:"caller_skip,omitempty"`    // 记录调用位置时额外跳过的栈帧数
+
+	Clock    Clock `json:"-"`                  // 记录日志时间的时钟，nil 使用系统时钟；时间戳的时区和精度由子 Writer 决定
+	Sequence bool  `json:"sequence,omitempty"` // 是否分配序号，启用后同一条日志在各子 Writer 中的序号一致
+
+	OnError func(err error, entries int) `json:"-"` // 子 Writer panic 时调用，未设置时限频输出到 stderr
+}
+
+// ChildStatus 子 Writer 的状态
+type ChildStatus struct {
+	Index      int    `json:"index"`       // 子 Writer 在 NewMultiWriter 参数中的下标
+	Healthy    bool   `json:"healthy"`     // 是否健康，不健康的子 Writer 不再接收日志
+	Dropped    uint64 `json:"dropped"`     // 因队列满或不健康被丢弃的日志数
+	QueueDepth int    `json:"queue_depth"` // 队列中等待写入的日志数（仅异步模式）
+}
+
 // MultiWriter 多路复用 Writer，可以同时写入多个 Writer（不依赖 go-zero）
 type MultiWriter struct {
-	writers []Writer
+	writers  []Writer
+	children []*multiChild
+	errors   *errorReporter
+
+	disableCaller bool
+	callerSkip    int
+	stamper       *stamper
+
+	mu     sync.RWMutex // 保护异步队列的关闭
+	closed bool
+}
+
+// multiChild 子 Writer 及其队列
+type multiChild struct {
+	index   int
+	writer  Writer
+	queue   chan multiCall // 同步模式下为 nil
+	done    chan struct{}
+	dropped atomic.Uint64
+	healthy atomic.Bool
+	errors  *errorReporter
+}
+
+// multiCall 队列中的一次写入，barrier 非 nil 时表示刷新屏障
+type multiCall struct {
+	write   func(w Writer)
+	barrier chan struct{}
 }
 
 // NewMultiWriter 创建一个多路复用 Writer
 func NewMultiWriter(writers ...Writer) *MultiWriter {
-	return &MultiWriter{
-		writers: writers,
+	return NewMultiWriterWithConfig(nil, writers...)
+}
+
+// NewMultiWriterWithConfig 使用配置创建一个多路复用 Writer
+func NewMultiWriterWithConfig(config *MultiConfig, writers ...Writer) *MultiWriter {
+	if config == nil {
+		config = &MultiConfig{}
+	}
+	queueSize := config.QueueSize
+	if queueSize <= 0 {
+		queueSize = defaultMultiQueueSize
+	}
+
+	m := &MultiWriter{
+		writers:       writers,
+		errors:        newErrorReporter("multi", config.OnError),
+		disableCaller: config.DisableCaller,
+		callerSkip:    config.CallerSkip,
+		stamper:       newStamper(config.Clock, false, 0, config.Sequence),
+	}
+	for i, w := range writers {
+		c := &multiChild{index: i, writer: w, errors: m.errors}
+		c.healthy.Store(true)
+		if config.Async {
+			c.queue = make(chan multiCall, queueSize)
+			c.done = make(chan struct{})
+			go c.run()
+		}
+		m.children = append(m.children, c)
+	}
+	return m
+}
+
+// write 构建一次日志条目（时间、trace/span），将同一条目交给所有子 Writer
+func (m *MultiWriter) write(ctx context.Context, level string, content any, fields []LogField) {
+	m.WriteEntry(buildEntry(ctx, level, content, fields))
+}
+
+// WriteEntry 将已构建好的日志条目交给所有子 Writer，实现 EntryWriter 接口；
+// 条目没有时间或 Caller 时在调用方 goroutine 中记录，异步模式下子 Writer 无法获取
+func (m *MultiWriter) WriteEntry(entry LogEntry) {
+	m.stamper.mark(&entry)
+	if entry.Caller == "" && !m.disableCaller {
+		entry.Caller = FindCaller(m.callerSkip)
+	}
+	m.each(func(w Writer) {
+		WriteEntry(w, entry)
+	})
+}
+
+// each 将一次写入分发给所有子 Writer
+func (m *MultiWriter) each(write func(w Writer)) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if m.closed {
+		return
+	}
+	for _, c := range m.children {
+		c.enqueue(write)
 	}
 }
 
 // Log 写入日志（核心方法）
 func (m *MultiWriter) Log(level string, content any, fields ...LogField) {
-	for _, w := range m.writers {
-		w.Log(level, content, fields...)
-	}
+	m.write(context.Background(), level, content, fields)
 }
 
 // Info 写入 info 级别日志
 func (m *MultiWriter) Info(content any, fields ...LogField) {
-	for _, w := range m.writers {
-		w.Info(content, fields...)
-	}
+	m.write(context.Background(), "info", content, fields)
 }
 
 // Error 写入 error 级别日志
 func (m *MultiWriter) Error(content any, fields ...LogField) {
-	for _, w := range m.writers {
-		w.Error(content, fields...)
-	}
+	m.write(context.Background(), "error", content, fields)
 }
 
 // Debug 写入 debug 级别日志
 func (m *MultiWriter) Debug(content any, fields ...LogField) {
-	for _, w := range m.writers {
-		w.Debug(content, fields...)
-	}
+	m.write(context.Background(), "debug", content, fields)
 }
 
 // Warn 写入 warn 级别日志
 func (m *MultiWriter) Warn(content any, fields ...LogField) {
-	for _, w := range m.writers {
-		w.Warn(content, fields...)
-	}
+	m.write(context.Background(), "warn", content, fields)
+}
+
+// LogCtx 使用 ctx 写入日志
+func (m *MultiWriter) LogCtx(ctx context.Context, level string, content any, fields ...LogField) {
+	m.write(ctx, level, content, fields)
 }
 
-// Close 关闭所有 Writer
+// InfoCtx 使用 ctx 写入 info 级别日志
+func (m *MultiWriter) InfoCtx(ctx context.Context, content any, fields ...LogField) {
+	m.LogCtx(ctx, "info", content, fields...)
+}
+
+// ErrorCtx 使用 ctx 写入 error 级别日志
+func (m *MultiWriter) ErrorCtx(ctx context.Context, content any, fields ...LogField) {
+	m.LogCtx(ctx, "error", content, fields...)
+}
+
+// DebugCtx 使用 ctx 写入 debug 级别日志
+func (m *MultiWriter) DebugCtx(ctx context.Context, content any, fields ...LogField) {
+	m.LogCtx(ctx, "debug", content, fields...)
+}
+
+// WarnCtx 使用 ctx 写入 warn 级别日志
+func (m *MultiWriter) WarnCtx(ctx context.Context, content any, fields ...LogField) {
+	m.LogCtx(ctx, "warn", content, fields...)
+}
+
+// Close 关闭所有 Writer，异步模式下先等待队列写完
 func (m *MultiWriter) Close() error {
+	m.stopQueues(context.Background())
+
 	var errs []error
 	for _, w := range m.writers {
 		if err := w.Close(); err != nil {
@@ -74,3 +202,192 @@ func (m *MultiWriter) Close() error {
 	}
 	return nil
 }
+
+// Flush 并发刷新所有实现了 Flusher 的子 Writer，未发送的条目数会被汇总到 *UnsentError；
+// 异步模式下先等待队列中已有的日志交给子 Writer
+func (m *MultiWriter) Flush(ctx context.Context) error {
+	if unsent := m.drain(ctx); unsent > 0 {
+		return &UnsentError{Unsent: unsent, Err: ctx.Err()}
+	}
+	return runAll(ctx, m.writers, "flushing", func(w Writer) error {
+		if f, ok := w.(Flusher); ok {
+			return f.Flush(ctx)
+		}
+		return nil
+	})
+}
+
+// CloseContext 并发关闭所有子 Writer，支持 ContextCloser 的子 Writer 会遵守 ctx 的截止时间
+func (m *MultiWriter) CloseContext(ctx context.Context) error {
+	unsent := m.stopQueues(ctx)
+	err := runAll(ctx, m.writers, "closing", func(w Writer) error {
+		if c, ok := w.(ContextCloser); ok {
+			return c.CloseContext(ctx)
+		}
+		return w.Close()
+	})
+	if unsent > 0 {
+		if ue, ok := err.(*UnsentError); ok {
+			unsent += ue.Unsent
+		}
+		return &UnsentError{Unsent: unsent, Err: ctx.Err()}
+	}
+	return err
+}
+
+// Stats 汇总所有实现了 StatsProvider 的子 Writer 的统计，Dropped 包含异步队列丢弃的日志
+func (m *MultiWriter) Stats() Stats {
+	var all []Stats
+	for _, w := range m.writers {
+		if p, ok := w.(StatsProvider); ok {
+			all = append(all, p.Stats())
+		}
+	}
+	stats := mergeStats(all)
+	stats.Dropped += m.Dropped()
+	return stats
+}
+
+// Dropped 返回因队列满或子 Writer 不健康而被丢弃的日志数（各子 Writer 之和）
+func (m *MultiWriter) Dropped() uint64 {
+	var n uint64
+	for _, c := range m.children {
+		n += c.dropped.Load()
+	}
+	return n
+}
+
+// Children 返回各子 Writer 的状态
+func (m *MultiWriter) Children() []ChildStatus {
+	status := make([]ChildStatus, len(m.children))
+	for i, c := range m.children {
+		status[i] = ChildStatus{
+			Index:      i,
+			Healthy:    c.healthy.Load(),
+			Dropped:    c.dropped.Load(),
+			QueueDepth: len(c.queue),
+		}
+	}
+	return status
+}
+
+// SetHealthy 标记子 Writer 是否健康，不健康的子 Writer 不再接收日志，其他子 Writer 不受影响
+func (m *MultiWriter) SetHealthy(index int, healthy bool) {
+	if index >= 0 && index < len(m.children) {
+		m.children[index].healthy.Store(healthy)
+	}
+}
+
+// drain 等待异步队列中已有的日志交给子 Writer，返回 ctx 结束时仍在队列中的日志数
+func (m *MultiWriter) drain(ctx context.Context) int {
+	m.mu.RLock()
+	if m.closed {
+		m.mu.RUnlock()
+		return 0
+	}
+	var barriers []chan struct{}
+	for _, c := range m.children {
+		if c.queue == nil {
+			continue
+		}
+		barrier := make(chan struct{})
+		select {
+		case c.queue <- multiCall{barrier: barrier}:
+			barriers = append(barriers, barrier)
+		case <-ctx.Done():
+		}
+	}
+	m.mu.RUnlock()
+
+	for _, barrier := range barriers {
+		select {
+		case <-barrier:
+		case <-ctx.Done():
+		}
+	}
+	if ctx.Err() != nil {
+		return m.queued()
+	}
+	return 0
+}
+
+// stopQueues 关闭异步队列并等待 goroutine 退出，返回 ctx 结束时仍在队列中的日志数
+func (m *MultiWriter) stopQueues(ctx context.Context) int {
+	m.mu.Lock()
+	if m.closed {
+		m.mu.Unlock()
+		return 0
+	}
+	m.closed = true
+	for _, c := range m.children {
+		if c.queue != nil {
+			close(c.queue)
+		}
+	}
+	m.mu.Unlock()
+
+	for _, c := range m.children {
+		if c.done == nil {
+			continue
+		}
+		select {
+		case <-c.done:
+		case <-ctx.Done():
+			return m.queued()
+		}
+	}
+	return 0
+}
+
+// queued 返回异步队列中的日志总数
+func (m *MultiWriter) queued() int {
+	n := 0
+	for _, c := range m.children {
+		n += len(c.queue)
+	}
+	return n
+}
+
+// enqueue 将写入交给子 Writer：同步模式直接调用，异步模式放入队列，队列满时丢弃
+func (c *multiChild) enqueue(write func(w Writer)) {
+	if !c.healthy.Load() {
+		c.dropped.Add(1)
+		return
+	}
+	if c.queue == nil {
+		c.call(write)
+		return
+	}
+	select {
+	case c.queue <- multiCall{write: write}:
+	default:
+		c.dropped.Add(1)
+	}
+}
+
+// run 异步模式下的写入 goroutine
+func (c *multiChild) run() {
+	defer close(c.done)
+	for call := range c.queue {
+		if call.barrier != nil {
+			close(call.barrier)
+			continue
+		}
+		if !c.healthy.Load() {
+			c.dropped.Add(1)
+			continue
+		}
+		c.call(call.write)
+	}
+}
+
+// call 调用子 Writer，panic 时将其标记为不健康
+func (c *multiChild) call(write func(w Writer)) {
+	defer func() {
+		if r := recover(); r != nil {
+			c.healthy.Store(false)
+			c.errors.report(fmt.Errorf("writer %d panicked and was marked unhealthy: %v", c.index, r), 1)
+		}
+	}()
+	write(c.writer)
+}
@@ -3,26 +3,28 @@ package writer
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"sync"
-	"time"
+	"strings"
+	"sync/atomic"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 // PostgresqlWriter PostgreSQL 写入器
 type PostgresqlWriter struct {
-	pool       *pgxpool.Pool
-	config     *PostgresConfig
-	buffer     []LogEntry
-	bufferMu   sync.Mutex
-	bufferSize int
-	tableName  string
-	ctx        context.Context
-	cancel     context.CancelFunc
-	wg         sync.WaitGroup
-	flushChan  chan struct{}
+	*BatchWriter
+	sink *pgSink
+}
+
+// pgSink 通过 COPY 写入 PostgreSQL 的 Sink
+type pgSink struct {
+	pool      *pgxpool.Pool
+	tableName string
+
+	bytesRaw atomic.Uint64
 }
 
 // NewPostgresqlWriter 创建一个新的 PostgreSQL Writer
@@ -37,45 +39,67 @@ func NewPostgresqlWriter(config *PostgresConfig) (*PostgresqlWriter, error) {
 	if config.TableName == "" {
 		config.TableName = "logs"
 	}
-	if config.BufferSize <= 0 {
-		config.BufferSize = 100
-	}
-	if config.FlushInterval <= 0 {
-		config.FlushInterval = 5 * time.Second
-	}
 
-	ctx, cancel := context.WithCancel(context.Background())
-
-	pool, err := pgxpool.New(ctx, config.DSN)
+	pool, err := pgxpool.New(context.Background(), config.DSN)
 	if err != nil {
-		cancel()
 		return nil, fmt.Errorf("failed to connect to postgres: %w", err)
 	}
 
-	w := &PostgresqlWriter{
-		pool:       pool,
-		config:     config,
-		buffer:     make([]LogEntry, 0, config.BufferSize),
-		bufferSize: config.BufferSize,
-		tableName:  config.TableName,
-		ctx:        ctx,
-		cancel:     cancel,
-		flushChan:  make(chan struct{}, 1),
+	sink := &pgSink{
+		pool:      pool,
+		tableName: config.TableName,
 	}
 
 	// 自动创建表
-	if err := w.ensureTable(); err != nil {
-		w.Close()
+	if err := sink.ensureTable(context.Background()); err != nil {
+		pool.Close()
 		return nil, err
 	}
 
-	w.wg.Add(1)
-	go w.flushLoop()
+	bw, err := NewBatchWriter(sink, config.batchConfig())
+	if err != nil {
+		pool.Close()
+		return nil, err
+	}
+	return &PostgresqlWriter{BatchWriter: bw, sink: sink}, nil
+}
 
-	return w, nil
+// batchConfig 转换为 BatchWriter 配置，PostgreSQL 按批次顺序串行写入
+func (c *PostgresConfig) batchConfig() *BatchConfig {
+	return &BatchConfig{
+		Name:               "postgres",
+		BufferSize:         c.BufferSize,
+		FlushInterval:      c.FlushInterval,
+		MinLevel:           c.MinLevel,
+		AtomicLevel:        c.AtomicLevel,
+		Processors:         c.Processors,
+		MaxBufferedEntries: c.MaxBufferedEntries,
+		MaxBufferedBytes:   c.MaxBufferedBytes,
+		OverflowPolicy:     c.OverflowPolicy,
+		BlockTimeout:       c.BlockTimeout,
+		Retry:              c.Retry,
+		FlushWorkers:       1,
+		MaxInFlightBatches: 1,
+		DeadLetter:         c.DeadLetter,
+		OnError:            c.OnError,
+		SpoolDir:           c.SpoolDir,
+		SpoolMaxBytes:      c.SpoolMaxBytes,
+		SpoolSegmentBytes:  c.SpoolSegmentBytes,
+		Service:            c.Service,
+		Environment:        c.Environment,
+		Version:            c.Version,
+		StaticFields:       c.StaticFields,
+		RuntimeInfo:        c.RuntimeInfo,
+		DisableCaller:      c.DisableCaller,
+		CallerSkip:         c.CallerSkip,
+		Clock:              c.Clock,
+		UTC:                c.UTC,
+		TimestampPrecision: c.TimestampPrecision,
+		Sequence:           c.Sequence,
+	}
 }
 
-func (w *PostgresqlWriter) ensureTable() error {
+func (s *pgSink) ensureTable(ctx context.Context) error {
 	query := fmt.Sprintf(`
 		CREATE TABLE IF NOT EXISTS %s (
 			id BIGSERIAL PRIMARY KEY,
@@ -85,111 +109,65 @@ func (w *PostgresqlWriter) ensureTable() error {
 			duration VARCHAR(50),
 			trace VARCHAR(100),
 			span VARCHAR(100),
-			fields JSONB
+			caller VARCHAR(255),
+			seq BIGINT,
+			fields JSONB,
+			metadata JSONB
 		);
 		CREATE INDEX IF NOT EXISTS idx_%s_timestamp ON %s(timestamp);
 		CREATE INDEX IF NOT EXISTS idx_%s_level ON %s(level);
 		CREATE INDEX IF NOT EXISTS idx_%s_trace ON %s(trace);
-	`, w.tableName, w.tableName, w.tableName, w.tableName, w.tableName, w.tableName, w.tableName)
+		ALTER TABLE %s ADD COLUMN IF NOT EXISTS metadata JSONB;
+		ALTER TABLE %s ADD COLUMN IF NOT EXISTS caller VARCHAR(255);
+		ALTER TABLE %s ADD COLUMN IF NOT EXISTS seq BIGINT;
+	`, s.tableName, s.tableName, s.tableName, s.tableName, s.tableName, s.tableName, s.tableName, s.tableName, s.tableName, s.tableName)
 
-	_, err := w.pool.Exec(w.ctx, query)
+	_, err := s.pool.Exec(ctx, query)
 	if err != nil {
 		return fmt.Errorf("failed to create table: %w", err)
 	}
 	return nil
 }
 
-// log 内部日志方法
-func (w *PostgresqlWriter) log(level string, content any, fields ...LogField) {
-	trace, span, duration := extractFields(fields)
-	entry := LogEntry{
-		Timestamp: time.Now().Format(time.RFC3339),
-		Level:     level,
-		Content:   FormatContent(content),
-		Duration:  duration,
-		Trace:     trace,
-		Span:      span,
-		Fields:    convertFields(fields),
-	}
-	w.AddEntry(entry)
-}
-
-// Log 写入日志
-func (w *PostgresqlWriter) Log(level string, content any, fields ...LogField) {
-	w.log(level, content, fields...)
-}
-
-// Info 写入 info 级别日志
-func (w *PostgresqlWriter) Info(content any, fields ...LogField) {
-	w.log("info", content, fields...)
-}
-
-// Error 写入 error 级别日志
-func (w *PostgresqlWriter) Error(content any, fields ...LogField) {
-	w.log("error", content, fields...)
-}
-
-// Debug 写入 debug 级别日志
-func (w *PostgresqlWriter) Debug(content any, fields ...LogField) {
-	w.log("debug", content, fields...)
-}
-
-// Warn 写入 warn 级别日志
-func (w *PostgresqlWriter) Warn(content any, fields ...LogField) {
-	w.log("warn", content, fields...)
-}
-
 // Ping 检查连接是否正常
 func (w *PostgresqlWriter) Ping(ctx context.Context) error {
-	return w.pool.Ping(ctx)
+	return w.sink.pool.Ping(ctx)
 }
 
-// Close 关闭写入器
-func (w *PostgresqlWriter) Close() error {
-	w.cancel()
-	w.wg.Wait()
-	w.flush()
-	w.pool.Close()
-	return nil
+// Target 返回表名，实现 TargetSink 接口
+func (s *pgSink) Target() string {
+	return s.tableName
 }
 
-// AddEntry 添加日志条目到缓冲区
-func (w *PostgresqlWriter) AddEntry(entry LogEntry) {
-	w.bufferMu.Lock()
-	w.buffer = append(w.buffer, entry)
-	shouldFlush := len(w.buffer) >= w.bufferSize
-	w.bufferMu.Unlock()
-
-	if shouldFlush {
-		select {
-		case w.flushChan <- struct{}{}:
-		default:
-		}
-	}
+// SinkStats 返回发送字节数（估算值），实现 SinkStatsProvider 接口
+func (s *pgSink) SinkStats() SinkStats {
+	n := s.bytesRaw.Load()
+	return SinkStats{BytesRaw: n, BytesSent: n}
 }
 
-// flush 刷新缓冲区
-func (w *PostgresqlWriter) flush() error {
-	w.bufferMu.Lock()
-	if len(w.buffer) == 0 {
-		w.bufferMu.Unlock()
-		return nil
-	}
-
-	entries := make([]LogEntry, len(w.buffer))
-	copy(entries, w.buffer)
-	w.buffer = w.buffer[:0]
-	w.bufferMu.Unlock()
-
-	if len(entries) == 0 {
-		return nil
-	}
+// Close 关闭连接池，由 BatchWriter 在 CloseContext 结束时调用
+func (s *pgSink) Close() error {
+	s.pool.Close()
+	return nil
+}
 
-	// 使用 CopyFrom 进行批量插入
+// WriteBatch 使用 CopyFrom 批量写入一批日志
+func (s *pgSink) WriteBatch(ctx context.Context, entries []LogEntry) error {
+	var size int64
 	rows := make([][]any, 0, len(entries))
 	for _, entry := range entries {
-		ts, _ := time.Parse(time.RFC3339, entry.Timestamp)
+		size += estimateEntrySize(entry)
+		ts := entry.Time()
 		fieldsJSON, _ := json.Marshal(entry.Fields)
+		var seq *int64
+		if entry.Seq > 0 {
+			n := int64(entry.Seq)
+			seq = &n
+		}
+		var metadataJSON []byte
+		if len(entry.Metadata) > 0 {
+			metadataJSON, _ = json.Marshal(entry.Metadata)
+		}
 		rows = append(rows, []any{
 			ts,
 			entry.Level,
@@ -197,40 +175,47 @@ func (w *PostgresqlWriter) flush() error {
 			entry.Duration,
 			entry.Trace,
 			entry.Span,
+			entry.Caller,
+			seq,
 			fieldsJSON,
+			metadataJSON,
 		})
 	}
 
-	_, err := w.pool.CopyFrom(
-		context.Background(),
-		pgx.Identifier{w.tableName},
-		[]string{"timestamp", "level", "content", "duration", "trace", "span", "fields"},
+	_, err := s.pool.CopyFrom(
+		ctx,
+		pgx.Identifier{s.tableName},
+		[]string{"timestamp", "level", "content", "duration", "trace", "span", "caller", "seq", "fields", "metadata"},
 		pgx.CopyFromRows(rows),
 	)
-
 	if err != nil {
-		return fmt.Errorf("failed to bulk insert logs to postgres: %w", err)
+		err = fmt.Errorf("failed to bulk insert logs to postgres: %w", err)
+		if isRetryablePgError(err) {
+			return Retryable(err)
+		}
+		return err
 	}
-
+	s.bytesRaw.Add(uint64(size))
 	return nil
 }
 
-// flushLoop 刷新循环
-func (w *PostgresqlWriter) flushLoop() {
-	defer w.wg.Done()
-
-	ticker := time.NewTicker(w.config.FlushInterval)
-	defer ticker.Stop()
-
-	for {
-		select {
-		case <-w.ctx.Done():
-			w.flush()
-			return
-		case <-ticker.C:
-			w.flush()
-		case <-w.flushChan:
-			w.flush()
-		}
+// isRetryablePgError 判断 PostgreSQL 错误是否可重试（连接异常、死锁、资源不足等）
+func isRetryablePgError(err error) bool {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		// 非服务端错误（网络、连接池等）均视为可重试
+		return true
+	}
+	switch {
+	case strings.HasPrefix(pgErr.Code, "08"), // connection exception
+		strings.HasPrefix(pgErr.Code, "53"), // insufficient resources
+		pgErr.Code == "40001",               // serialization_failure
+		pgErr.Code == "40P01",               // deadlock_detected
+		pgErr.Code == "57P01",               // admin_shutdown
+		pgErr.Code == "57P02",               // crash_shutdown
+		pgErr.Code == "57P03":               // cannot_connect_now
+		return true
+	default:
+		return false
 	}
 }
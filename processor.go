@@ -0,0 +1,86 @@
+package writer
+
+import "strings"
+
+// Processor 日志处理器，在日志条目构建后、进入缓冲区前按顺序执行，
+// 可以修改或补充条目，返回 false 时丢弃该条日志。处理器在调用方 goroutine 中执行
+type Processor interface {
+	Process(entry *LogEntry) bool
+}
+
+// ProcessorFunc 将普通函数适配为 Processor
+type ProcessorFunc func(entry *LogEntry) bool
+
+// Process 实现 Processor 接口
+func (f ProcessorFunc) Process(entry *LogEntry) bool {
+	return f(entry)
+}
+
+// ApplyProcessors 依次执行处理器，任一处理器返回 false 时停止并返回 false。
+// 执行前会复制 entry.Fields，处理器可以直接修改，不会影响共享同一条目的其他 Writer
+func ApplyProcessors(processors []Processor, entry *LogEntry) bool {
+	if len(processors) == 0 {
+		return true
+	}
+	if entry.Fields != nil {
+		fields := make(map[string]interface{}, len(entry.Fields))
+		for key, value := range entry.Fields {
+			fields[key] = value
+		}
+		entry.Fields = fields
+	}
+	for _, p := range processors {
+		if !p.Process(entry) {
+			return false
+		}
+	}
+	return true
+}
+
+// AddField 为每条日志添加字段，已存在同名字段时不覆盖
+func AddField(key string, value any) Processor {
+	return ProcessorFunc(func(entry *LogEntry) bool {
+		if entry.Fields == nil {
+			entry.Fields = make(map[string]interface{})
+		}
+		if _, ok := entry.Fields[key]; !ok {
+			entry.Fields[key] = value
+		}
+		return true
+	})
+}
+
+// RenameField 将字段 from 重命名为 to
+func RenameField(from, to string) Processor {
+	return ProcessorFunc(func(entry *LogEntry) bool {
+		if value, ok := entry.Fields[from]; ok {
+			delete(entry.Fields, from)
+			entry.Fields[to] = value
+		}
+		return true
+	})
+}
+
+// RemoveField 移除字段
+func RemoveField(keys ...string) Processor {
+	return ProcessorFunc(func(entry *LogEntry) bool {
+		for _, key := range keys {
+			delete(entry.Fields, key)
+		}
+		return true
+	})
+}
+
+// DropIf 丢弃满足条件的日志
+func DropIf(pred func(entry *LogEntry) bool) Processor {
+	return ProcessorFunc(func(entry *LogEntry) bool {
+		return !pred(entry)
+	})
+}
+
+// DropContent 丢弃内容包含 substr 的日志（如健康检查请求）
+func DropContent(substr string) Processor {
+	return DropIf(func(entry *LogEntry) bool {
+		return strings.Contains(entry.Content, substr)
+	})
+}
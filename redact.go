@@ -0,0 +1,265 @@
+package writer
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// RedactMode 脱敏方式
+type RedactMode string
+
+const (
+	// RedactMask 替换为掩码（默认）
+	RedactMask RedactMode = "mask"
+	// RedactHash 替换为 SHA-256 摘要前缀，相同的值得到相同的摘要，便于关联排查
+	RedactHash RedactMode = "hash"
+	// RedactRemove 移除字段；内容中的匹配部分替换为空字符串
+	RedactRemove RedactMode = "remove"
+)
+
+const defaultRedactMask = "[REDACTED]"
+
+// 常用的敏感信息正则，可用于 RedactConfig.Patterns
+const (
+	// PatternEmail 电子邮件地址
+	PatternEmail = `[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`
+	// PatternChinaIDCard 中国大陆 18 位居民身份证号
+	PatternChinaIDCard = `\b[1-9]\d{5}(?:18|19|20)\d{2}(?:0[1-9]|1[0-2])(?:0[1-9]|[12]\d|3[01])\d{3}[\dXx]\b`
+	// PatternChinaMobile 中国大陆手机号
+	PatternChinaMobile = `\b1[3-9]\d{9}\b`
+)
+
+var (
+	// jwtPattern JWT（header.payload.signature，header 与 payload 以 eyJ 开头）
+	jwtPattern = regexp.MustCompile(`\beyJ[A-Za-z0-9_\-]+\.eyJ[A-Za-z0-9_\-]+\.[A-Za-z0-9_\-]*`)
+	// bearerPattern Bearer 令牌，只替换令牌部分
+	bearerPattern = regexp.MustCompile(`(?i)\bbearer\s+([A-Za-z0-9\-._~+/]+=*)`)
+	// cardPattern 13~19 位数字，允许空格或短横线分隔，匹配后再做 Luhn 校验
+	cardPattern = regexp.MustCompile(`\b\d(?:[ \-]?\d){12,18}\b`)
+)
+
+// RedactConfig 脱敏处理器配置
+type RedactConfig struct {
+	Keys             []string   `json:"keys,omitempty"`               // 需要脱敏的字段名（不区分大小写），嵌套的 map 字段以及字符串中 JSON、key=value 形式的同名字段同样生效
+	Patterns         []string   `json:"patterns,omitempty"`           // 应用于 content、字符串和 []byte 字段值的正则，有捕获组时只替换第一个捕获组
+	DetectJWT        bool       `json:"detect_jwt,omitempty"`         // 检测 JWT
+	DetectBearer     bool       `json:"detect_bearer,omitempty"`      // 检测 Bearer 令牌
+	DetectCreditCard bool       `json:"detect_credit_card,omitempty"` // 检测信用卡号（Luhn 校验）
+	Mode             RedactMode `json:"mode,omitempty"`               // 脱敏方式，默认 mask
+	Mask             string     `json:"mask,omitempty"`               // 掩码，默认 [REDACTED]
+	HashSalt         string     `json:"hash_salt,omitempty"`          // hash 方式的盐值，避免摘要被字典反查
+}
+
+// redactRule 一条内容脱敏规则
+type redactRule struct {
+	re    *regexp.Regexp
+	valid func(match string) bool // 二次校验，nil 表示全部匹配都脱敏
+}
+
+// Redactor 脱敏处理器，对字段名命中黑名单的字段，以及 content、字符串和 []byte 字段值中
+// 匹配规则或以 JSON、key=value 形式出现的黑名单字段值脱敏
+type Redactor struct {
+	keys  map[string]bool
+	rules []redactRule
+	mode  RedactMode
+	mask  string
+	salt  string
+}
+
+// NewRedactor 创建脱敏处理器
+func NewRedactor(config RedactConfig) (*Redactor, error) {
+	r := &Redactor{
+		keys: make(map[string]bool, len(config.Keys)),
+		mode: config.Mode,
+		mask: config.Mask,
+		salt: config.HashSalt,
+	}
+	switch r.mode {
+	case "":
+		r.mode = RedactMask
+	case RedactMask, RedactHash, RedactRemove:
+	default:
+		return nil, fmt.Errorf("unknown redact mode: %q", config.Mode)
+	}
+	if r.mask == "" {
+		r.mask = defaultRedactMask
+	}
+	for _, key := range config.Keys {
+		r.keys[strings.ToLower(key)] = true
+	}
+	r.rules = append(r.rules, keyRules(config.Keys)...)
+	for _, pattern := range config.Patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redact pattern %q: %w", pattern, err)
+		}
+		r.rules = append(r.rules, redactRule{re: re})
+	}
+	if config.DetectJWT {
+		r.rules = append(r.rules, redactRule{re: jwtPattern})
+	}
+	if config.DetectBearer {
+		r.rules = append(r.rules, redactRule{re: bearerPattern})
+	}
+	if config.DetectCreditCard {
+		r.rules = append(r.rules, redactRule{re: cardPattern, valid: luhnValid})
+	}
+	return r, nil
+}
+
+// keyRules 根据字段名黑名单生成规则，脱敏字符串中以 JSON（"key": "value"、"key": 123）
+// 和表单/查询参数（key=value）形式出现的值，用于请求体等以字符串或 []byte 记录的内容
+func keyRules(keys []string) []redactRule {
+	if len(keys) == 0 {
+		return nil
+	}
+	quoted := make([]string, len(keys))
+	for i, key := range keys {
+		quoted[i] = regexp.QuoteMeta(key)
+	}
+	names := strings.Join(quoted, "|")
+	return []redactRule{
+		{re: regexp.MustCompile(`(?i)"(?:` + names + `)"\s*:\s*"((?:[^"\\]|\\.)*)"`)},
+		{re: regexp.MustCompile(`(?i)"(?:` + names + `)"\s*:\s*(-?\d[\d.eE+\-]*)`)},
+		{re: regexp.MustCompile(`(?i)\b(?:` + names + `)=([^&\s;,"]*)`)},
+	}
+}
+
+// Process 实现 Processor 接口
+func (r *Redactor) Process(entry *LogEntry) bool {
+	entry.Content = r.redactString(entry.Content)
+	for key, value := range entry.Fields {
+		if r.keys[strings.ToLower(key)] {
+			if r.mode == RedactRemove {
+				delete(entry.Fields, key)
+			} else {
+				entry.Fields[key] = r.replace(fmt.Sprint(value))
+			}
+			continue
+		}
+		entry.Fields[key] = r.redactValue(value)
+	}
+	return true
+}
+
+// redactValue 脱敏字段值，嵌套的 map 和切片会被复制，不修改调用方传入的值
+func (r *Redactor) redactValue(value any) any {
+	switch v := value.(type) {
+	case string:
+		return r.redactString(v)
+	case []byte:
+		if redacted := r.redactString(string(v)); redacted != string(v) {
+			return []byte(redacted)
+		}
+		return v
+	case map[string]interface{}:
+		copied := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			if r.keys[strings.ToLower(key)] {
+				if r.mode != RedactRemove {
+					copied[key] = r.replace(fmt.Sprint(item))
+				}
+				continue
+			}
+			copied[key] = r.redactValue(item)
+		}
+		return copied
+	case map[string]string:
+		copied := make(map[string]string, len(v))
+		for key, item := range v {
+			if r.keys[strings.ToLower(key)] {
+				if r.mode != RedactRemove {
+					copied[key] = r.replace(item)
+				}
+				continue
+			}
+			copied[key] = r.redactString(item)
+		}
+		return copied
+	case []interface{}:
+		copied := make([]interface{}, len(v))
+		for i, item := range v {
+			copied[i] = r.redactValue(item)
+		}
+		return copied
+	case []string:
+		copied := make([]string, len(v))
+		for i, item := range v {
+			copied[i] = r.redactString(item)
+		}
+		return copied
+	default:
+		return value
+	}
+}
+
+// redactString 对字符串依次应用全部规则
+func (r *Redactor) redactString(s string) string {
+	for _, rule := range r.rules {
+		s = r.applyRule(rule, s)
+	}
+	return s
+}
+
+// applyRule 替换字符串中匹配规则的部分，有捕获组时只替换第一个捕获组
+func (r *Redactor) applyRule(rule redactRule, s string) string {
+	matches := rule.re.FindAllStringSubmatchIndex(s, -1)
+	if len(matches) == 0 {
+		return s
+	}
+	var b strings.Builder
+	last := 0
+	for _, m := range matches {
+		start, end := m[0], m[1]
+		if len(m) >= 4 && m[2] >= 0 {
+			start, end = m[2], m[3]
+		}
+		match := s[start:end]
+		if rule.valid != nil && !rule.valid(match) {
+			continue
+		}
+		b.WriteString(s[last:start])
+		if r.mode != RedactRemove {
+			b.WriteString(r.replace(match))
+		}
+		last = end
+	}
+	if last == 0 {
+		return s
+	}
+	b.WriteString(s[last:])
+	return b.String()
+}
+
+// replace 返回敏感值的替换文本
+func (r *Redactor) replace(value string) string {
+	if r.mode == RedactHash {
+		sum := sha256.Sum256([]byte(r.salt + value))
+		return "sha256:" + hex.EncodeToString(sum[:8])
+	}
+	return r.mask
+}
+
+// luhnValid 对数字串（忽略空格和短横线）做 Luhn 校验
+func luhnValid(s string) bool {
+	sum, n := 0, 0
+	for i := len(s) - 1; i >= 0; i-- {
+		c := s[i]
+		if c == ' ' || c == '-' {
+			continue
+		}
+		d := int(c - '0')
+		if n%2 == 1 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		n++
+	}
+	return n >= 13 && n <= 19 && sum%10 == 0
+}
@@ -0,0 +1,65 @@
+package writer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRedactorKeysInBodies(t *testing.T) {
+	redactor, err := NewRedactor(RedactConfig{Keys: []string{"password", "token"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		value any
+		want  string
+	}{
+		{"json string", `{"user":"bob","password":"p@ss"}`, `{"user":"bob","password":"[REDACTED]"}`},
+		{"json bytes", []byte(`{"Password": "p@ss"}`), `{"Password": "[REDACTED]"}`},
+		{"json number", `{"token":123456}`, `{"token":[REDACTED]}`},
+		{"json escaped quote", `{"password":"a\"b","ok":1}`, `{"password":"[REDACTED]","ok":1}`},
+		{"form", "user=bob&password=p%40ss&x=1", "user=bob&password=[REDACTED]&x=1"},
+		{"query", "/login?token=abc def", "/login?token=[REDACTED] def"},
+		{"unrelated key", "old_password=keep", "old_password=keep"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry := LogEntry{Fields: map[string]interface{}{"body": tt.value}}
+			redactor.Process(&entry)
+			got := entry.Fields["body"]
+			if b, ok := got.([]byte); ok {
+				got = string(b)
+			}
+			if got != tt.want {
+				t.Fatalf("body = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRedactorKeysInContent(t *testing.T) {
+	redactor, err := NewRedactor(RedactConfig{Keys: []string{"password"}, Mode: RedactRemove})
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry := LogEntry{Content: `request body: {"password":"secret"}`}
+	redactor.Process(&entry)
+	if strings.Contains(entry.Content, "secret") {
+		t.Fatalf("content = %q, still contains the password", entry.Content)
+	}
+}
+
+func TestRedactorBytesUnchanged(t *testing.T) {
+	redactor, err := NewRedactor(RedactConfig{Keys: []string{"password"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	body := []byte(`{"user":"bob"}`)
+	entry := LogEntry{Fields: map[string]interface{}{"body": body}}
+	redactor.Process(&entry)
+	if got, ok := entry.Fields["body"].([]byte); !ok || &got[0] != &body[0] {
+		t.Fatalf("body = %v, want the original slice", entry.Fields["body"])
+	}
+}
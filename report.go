@@ -0,0 +1,55 @@
+package writer
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// errorReportInterval 未设置 OnError 时输出到 stderr 的最小间隔
+const errorReportInterval = 10 * time.Second
+
+// errorReporter 上报后台刷新失败，未设置回调时限频输出到 stderr
+type errorReporter struct {
+	name       string
+	onError    func(err error, entries int)
+	mu         sync.Mutex
+	last       time.Time
+	suppressed int
+}
+
+// newErrorReporter 创建错误上报器，name 用于 stderr 输出中标识写入器
+func newErrorReporter(name string, onError func(err error, entries int)) *errorReporter {
+	return &errorReporter{name: name, onError: onError}
+}
+
+// report 上报一次刷新失败
+func (r *errorReporter) report(err error, entries int) {
+	if err == nil {
+		return
+	}
+	if r.onError != nil {
+		r.onError(err, entries)
+		return
+	}
+
+	r.mu.Lock()
+	now := time.Now()
+	if !r.last.IsZero() && now.Sub(r.last) < errorReportInterval {
+		r.suppressed++
+		r.mu.Unlock()
+		return
+	}
+	suppressed := r.suppressed
+	r.last = now
+	r.suppressed = 0
+	r.mu.Unlock()
+
+	if suppressed > 0 {
+		fmt.Fprintf(os.Stderr, "[es-log-writer] %s: failed to flush %d log entries: %v (%d similar errors suppressed)\n",
+			r.name, entries, err, suppressed)
+	} else {
+		fmt.Fprintf(os.Stderr, "[es-log-writer] %s: failed to flush %d log entries: %v\n", r.name, entries, err)
+	}
+}
@@ -0,0 +1,128 @@
+package writer
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand/v2"
+	"time"
+)
+
+// RetryPolicy 批量写入失败时的重试策略
+type RetryPolicy struct {
+	MaxAttempts          int           `json:"max_attempts"`                     // 最大尝试次数（含首次），1 表示不重试
+	InitialBackoff       time.Duration `json:"initial_backoff"`                  // 首次重试前的等待时间
+	MaxBackoff           time.Duration `json:"max_backoff"`                      // 单次等待时间上限
+	Multiplier           float64       `json:"multiplier,omitempty"`             // 退避倍数
+	Jitter               float64       `json:"jitter,omitempty"`                 // 抖动比例（0~1），等待时间在 ±Jitter 范围内随机
+	RetryableStatusCodes []int         `json:"retryable_status_codes,omitempty"` // 可重试的 HTTP 状态码
+}
+
+// DefaultRetryPolicy 返回默认重试策略
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts:          5,
+		InitialBackoff:       200 * time.Millisecond,
+		MaxBackoff:           10 * time.Second,
+		Multiplier:           2,
+		Jitter:               0.2,
+		RetryableStatusCodes: []int{429, 502, 503, 504},
+	}
+}
+
+// normalize 补全未设置的字段
+func (p *RetryPolicy) normalize() *RetryPolicy {
+	def := DefaultRetryPolicy()
+	if p == nil {
+		return def
+	}
+	policy := *p
+	if policy.MaxAttempts <= 0 {
+		policy.MaxAttempts = def.MaxAttempts
+	}
+	if policy.InitialBackoff <= 0 {
+		policy.InitialBackoff = def.InitialBackoff
+	}
+	if policy.MaxBackoff < policy.InitialBackoff {
+		policy.MaxBackoff = policy.InitialBackoff
+	}
+	if policy.Multiplier < 1 {
+		policy.Multiplier = def.Multiplier
+	}
+	if policy.Jitter < 0 {
+		policy.Jitter = 0
+	} else if policy.Jitter > 1 {
+		policy.Jitter = 1
+	}
+	if policy.RetryableStatusCodes == nil {
+		policy.RetryableStatusCodes = def.RetryableStatusCodes
+	}
+	return &policy
+}
+
+// backoff 计算第 attempt 次失败后的等待时间
+func (p *RetryPolicy) backoff(attempt int) time.Duration {
+	d := float64(p.InitialBackoff) * math.Pow(p.Multiplier, float64(attempt-1))
+	if d > float64(p.MaxBackoff) {
+		d = float64(p.MaxBackoff)
+	}
+	if p.Jitter > 0 {
+		d += d * p.Jitter * (2*rand.Float64() - 1)
+	}
+	return time.Duration(d)
+}
+
+// retryableStatus 判断 HTTP 状态码是否可重试
+func (p *RetryPolicy) retryableStatus(code int) bool {
+	for _, c := range p.RetryableStatusCodes {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
+
+// do 执行 fn，返回可重试错误时按策略退避重试，返回实际尝试次数
+func (p *RetryPolicy) do(ctx context.Context, fn func(attempt int) error) (int, error) {
+	for attempt := 1; ; attempt++ {
+		err := fn(attempt)
+		if err == nil || !IsRetryable(err) || attempt >= p.MaxAttempts {
+			return attempt, err
+		}
+
+		timer := time.NewTimer(p.backoff(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return attempt, err
+		case <-timer.C:
+		}
+	}
+}
+
+// retryableError 标记可以重试的错误
+type retryableError struct {
+	err error
+}
+
+func (e *retryableError) Error() string {
+	return e.err.Error()
+}
+
+func (e *retryableError) Unwrap() error {
+	return e.err
+}
+
+// Retryable 将错误标记为可重试，Sink 返回的未标记错误视为永久失败
+func Retryable(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &retryableError{err: err}
+}
+
+// IsRetryable 判断错误是否可重试
+func IsRetryable(err error) bool {
+	var re *retryableError
+	return errors.As(err, &re)
+}
@@ -0,0 +1,97 @@
+package writer
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRetryPolicyBackoffGrowsToMax(t *testing.T) {
+	p := (&RetryPolicy{InitialBackoff: 100 * time.Millisecond, MaxBackoff: time.Second, Multiplier: 2}).normalize()
+	want := []time.Duration{
+		100 * time.Millisecond,
+		200 * time.Millisecond,
+		400 * time.Millisecond,
+		800 * time.Millisecond,
+		time.Second,
+		time.Second,
+	}
+	for i, w := range want {
+		if got := p.backoff(i + 1); got != w {
+			t.Fatalf("backoff(%d) = %v, want %v", i+1, got, w)
+		}
+	}
+}
+
+func TestRetryPolicyBackoffJitterRange(t *testing.T) {
+	p := (&RetryPolicy{InitialBackoff: 100 * time.Millisecond, MaxBackoff: time.Second, Multiplier: 2, Jitter: 0.2}).normalize()
+	seen := make(map[time.Duration]bool)
+	for i := 0; i < 1000; i++ {
+		d := p.backoff(2)
+		if d < 160*time.Millisecond || d > 240*time.Millisecond {
+			t.Fatalf("backoff(2) = %v, want within 200ms ±20%%", d)
+		}
+		seen[d] = true
+	}
+	if len(seen) < 2 {
+		t.Fatal("backoff with jitter always returned the same value")
+	}
+}
+
+func TestRetryPolicyDo(t *testing.T) {
+	p := (&RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond}).normalize()
+	permanent := errors.New("bad request")
+	unavailable := errors.New("unavailable")
+
+	tests := []struct {
+		name         string
+		fail         int   // 前 fail 次返回 err
+		err          error // 失败时返回的错误
+		wantAttempts int
+		wantErr      error
+	}{
+		{"success", 0, nil, 1, nil},
+		{"non-retryable stops immediately", 3, permanent, 1, permanent},
+		{"retryable then success", 2, Retryable(unavailable), 3, nil},
+		{"retryable exhausts MaxAttempts", 5, Retryable(unavailable), 3, unavailable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			attempts, err := p.do(context.Background(), func(attempt int) error {
+				calls++
+				if attempt != calls {
+					t.Fatalf("attempt = %d, want %d", attempt, calls)
+				}
+				if calls <= tt.fail {
+					return tt.err
+				}
+				return nil
+			})
+			if attempts != tt.wantAttempts || calls != tt.wantAttempts {
+				t.Fatalf("attempts = %d, calls = %d, want %d", attempts, calls, tt.wantAttempts)
+			}
+			if !errors.Is(err, tt.wantErr) || (err == nil) != (tt.wantErr == nil) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRetryPolicyDoStopsWhenContextEnds(t *testing.T) {
+	p := (&RetryPolicy{MaxAttempts: 5, InitialBackoff: time.Minute}).normalize()
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	attempts, err := p.do(ctx, func(attempt int) error {
+		return Retryable(errors.New("unavailable"))
+	})
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("do returned after %v, want the backoff wait to end with ctx", elapsed)
+	}
+	if attempts != 1 || !IsRetryable(err) {
+		t.Fatalf("attempts = %d, err = %v, want 1 and the last retryable error", attempts, err)
+	}
+}
@@ -0,0 +1,247 @@
+package writer
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// RouteMatcher 路由谓词，根据级别、内容和字段判断日志是否进入该路由
+type RouteMatcher func(level, content string, fields []LogField) bool
+
+// Route 一条路由，日志会写入所有匹配的路由
+type Route struct {
+	Name   string       // 路由名称，用于错误信息
+	Match  RouteMatcher // 路由谓词，nil 表示匹配全部日志
+	Writer Writer       // 目标 Writer
+}
+
+// RoutingWriter 按路由谓词分发日志的 Writer，未匹配任何路由的日志写入默认 Writer
+type RoutingWriter struct {
+	routes   []Route
+	fallback Writer
+	writers  []Writer // 去重后的全部目标 Writer，用于关闭、刷新和统计
+}
+
+// NewRoutingWriter 创建一个路由 Writer，fallback 为 nil 时未匹配的日志被丢弃
+func NewRoutingWriter(fallback Writer, routes ...Route) *RoutingWriter {
+	r := &RoutingWriter{
+		routes:   routes,
+		fallback: fallback,
+	}
+	for _, route := range routes {
+		r.writers = appendUnique(r.writers, route.Writer)
+	}
+	if fallback != nil {
+		r.writers = appendUnique(r.writers, fallback)
+	}
+	return r
+}
+
+// appendUnique 追加 w，已存在时忽略
+func appendUnique(writers []Writer, w Writer) []Writer {
+	if w == nil {
+		return writers
+	}
+	if reflect.TypeOf(w).Comparable() {
+		for _, existing := range writers {
+			if reflect.TypeOf(existing) == reflect.TypeOf(w) && existing == w {
+				return writers
+			}
+		}
+	}
+	return append(writers, w)
+}
+
+// log 构建一次日志条目，交给所有匹配的路由；条目不记录时间，由接收的 Writer 按各自的时钟记录
+func (r *RoutingWriter) log(ctx context.Context, level string, content any, fields []LogField) {
+	entry := buildEntry(ctx, level, content, fields)
+	r.route(entry, mergeFields(FieldsFromContext(ctx), fields))
+}
+
+// route 将条目交给所有匹配的路由，未匹配时交给默认 Writer；fields 用于路由谓词
+func (r *RoutingWriter) route(entry LogEntry, fields []LogField) {
+	matched := false
+	for _, route := range r.routes {
+		if route.Match == nil || route.Match(entry.Level, entry.Content, fields) {
+			matched = true
+			WriteEntry(route.Writer, entry)
+		}
+	}
+	if !matched && r.fallback != nil {
+		WriteEntry(r.fallback, entry)
+	}
+}
+
+// WriteEntry 将已构建好的日志条目交给所有匹配的路由，实现 EntryWriter 接口
+func (r *RoutingWriter) WriteEntry(entry LogEntry) {
+	r.route(entry, entryFields(entry))
+}
+
+// Log 写入日志（核心方法）
+func (r *RoutingWriter) Log(level string, content any, fields ...LogField) {
+	r.log(context.Background(), level, content, fields)
+}
+
+// Info 写入 info 级别日志
+func (r *RoutingWriter) Info(content any, fields ...LogField) {
+	r.log(context.Background(), "info", content, fields)
+}
+
+// Error 写入 error 级别日志
+func (r *RoutingWriter) Error(content any, fields ...LogField) {
+	r.log(context.Background(), "error", content, fields)
+}
+
+// Debug 写入 debug 级别日志
+func (r *RoutingWriter) Debug(content any, fields ...LogField) {
+	r.log(context.Background(), "debug", content, fields)
+}
+
+// Warn 写入 warn 级别日志
+func (r *RoutingWriter) Warn(content any, fields ...LogField) {
+	r.log(context.Background(), "warn", content, fields)
+}
+
+// LogCtx 使用 ctx 写入日志
+func (r *RoutingWriter) LogCtx(ctx context.Context, level string, content any, fields ...LogField) {
+	r.log(ctx, level, content, fields)
+}
+
+// InfoCtx 使用 ctx 写入 info 级别日志
+func (r *RoutingWriter) InfoCtx(ctx context.Context, content any, fields ...LogField) {
+	r.LogCtx(ctx, "info", content, fields...)
+}
+
+// ErrorCtx 使用 ctx 写入 error 级别日志
+func (r *RoutingWriter) ErrorCtx(ctx context.Context, content any, fields ...LogField) {
+	r.LogCtx(ctx, "error", content, fields...)
+}
+
+// DebugCtx 使用 ctx 写入 debug 级别日志
+func (r *RoutingWriter) DebugCtx(ctx context.Context, content any, fields ...LogField) {
+	r.LogCtx(ctx, "debug", content, fields...)
+}
+
+// WarnCtx 使用 ctx 写入 warn 级别日志
+func (r *RoutingWriter) WarnCtx(ctx context.Context, content any, fields ...LogField) {
+	r.LogCtx(ctx, "warn", content, fields...)
+}
+
+// Close 关闭所有目标 Writer
+func (r *RoutingWriter) Close() error {
+	var errs []error
+	for _, w := range r.writers {
+		if err := w.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("errors closing writers: %v", errs)
+	}
+	return nil
+}
+
+// Flush 并发刷新所有实现了 Flusher 的目标 Writer
+func (r *RoutingWriter) Flush(ctx context.Context) error {
+	return runAll(ctx, r.writers, "flushing", func(w Writer) error {
+		if f, ok := w.(Flusher); ok {
+			return f.Flush(ctx)
+		}
+		return nil
+	})
+}
+
+// CloseContext 并发关闭所有目标 Writer
+func (r *RoutingWriter) CloseContext(ctx context.Context) error {
+	return runAll(ctx, r.writers, "closing", func(w Writer) error {
+		if c, ok := w.(ContextCloser); ok {
+			return c.CloseContext(ctx)
+		}
+		return w.Close()
+	})
+}
+
+// Stats 汇总所有实现了 StatsProvider 的目标 Writer 的统计
+func (r *RoutingWriter) Stats() Stats {
+	var all []Stats
+	for _, w := range r.writers {
+		if p, ok := w.(StatsProvider); ok {
+			all = append(all, p.Stats())
+		}
+	}
+	return mergeStats(all)
+}
+
+// MatchMinLevel 匹配不低于 min 级别的日志
+func MatchMinLevel(min Level) RouteMatcher {
+	return func(level, _ string, _ []LogField) bool {
+		return min.Enabled(level)
+	}
+}
+
+// MatchLevels 匹配指定级别的日志（按原始级别名称，不区分大小写）
+func MatchLevels(levels ...string) RouteMatcher {
+	return func(level, _ string, _ []LogField) bool {
+		for _, l := range levels {
+			if strings.EqualFold(l, level) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// MatchField 匹配包含字段 key 且值等于 value 的日志，value 为 nil 时只要求字段存在
+func MatchField(key string, value any) RouteMatcher {
+	return func(_, _ string, fields []LogField) bool {
+		for _, f := range fields {
+			if f.Key != key {
+				continue
+			}
+			if value == nil || fmt.Sprint(f.Value) == fmt.Sprint(value) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// MatchContent 匹配内容包含 substr 的日志
+func MatchContent(substr string) RouteMatcher {
+	return func(_, content string, _ []LogField) bool {
+		return strings.Contains(content, substr)
+	}
+}
+
+// MatchAll 所有谓词都匹配时匹配
+func MatchAll(matchers ...RouteMatcher) RouteMatcher {
+	return func(level, content string, fields []LogField) bool {
+		for _, m := range matchers {
+			if !m(level, content, fields) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// MatchAny 任一谓词匹配时匹配
+func MatchAny(matchers ...RouteMatcher) RouteMatcher {
+	return func(level, content string, fields []LogField) bool {
+		for _, m := range matchers {
+			if m(level, content, fields) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// MatchNot 谓词不匹配时匹配
+func MatchNot(matcher RouteMatcher) RouteMatcher {
+	return func(level, content string, fields []LogField) bool {
+		return !matcher(level, content, fields)
+	}
+}
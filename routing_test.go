@@ -0,0 +1,36 @@
+package writer
+
+import (
+	"testing"
+	"time"
+)
+
+// fixedClock 返回固定时间的时钟
+func fixedClock(t time.Time) Clock {
+	return ClockFunc(func() time.Time { return t })
+}
+
+func TestRoutingWriterUsesChildClock(t *testing.T) {
+	now := time.Date(2024, 1, 2, 3, 4, 5, 123456789, time.UTC)
+	sink := &testSink{}
+	config := testBatchConfig()
+	config.Clock = fixedClock(now)
+	config.UTC = true
+	child, err := NewBatchWriter(sink, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := NewRoutingWriter(nil, Route{Name: "errors", Match: MatchMinLevel(LevelError), Writer: child})
+	r.Error("routed")
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if n := sink.count(); n != 1 {
+		t.Fatalf("written = %d, want 1", n)
+	}
+	if got, want := sink.written[0].Timestamp, "2024-01-02T03:04:05.123456789Z"; got != want {
+		t.Fatalf("Timestamp = %q, want %q from the child's clock", got, want)
+	}
+}
@@ -0,0 +1,392 @@
+package writer
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	defaultSpoolMaxBytes     = 1 << 30
+	defaultSpoolSegmentBytes = 16 << 20
+	spoolSegmentExt          = ".seg"
+)
+
+// errSpoolFull 磁盘队列已达到 SpoolMaxBytes
+var errSpoolFull = errors.New("spool is full")
+
+// spoolSegment 磁盘队列中的一个段文件
+type spoolSegment struct {
+	size    int64
+	pending int  // 尚未确认的条目数
+	sealed  bool // 不再追加写入
+}
+
+// spool 磁盘预写队列，日志进入内存缓冲区前先以 NDJSON 追加写入段文件，
+// 段内全部条目被确认（写入成功、转入死信或被丢弃）后删除段文件。
+// 缓冲区已满时条目只保存在磁盘上（积压），由 load 在缓冲区腾出空间后按写入顺序读入，
+// 积压未读完前新条目也进入积压，保证读入顺序与写入顺序一致
+type spool struct {
+	dir          string
+	maxBytes     int64
+	segmentBytes int64
+
+	mu       sync.Mutex
+	segments map[uint64]*spoolSegment
+	total    int64
+	active   *os.File // 当前追加写入的段，nil 表示下次写入时新建
+	activeID uint64
+	nextID   uint64
+
+	backlog  int           // 只保存在磁盘上、尚未读入缓冲区的条目数
+	replay   int           // 积压中上次运行遗留的条目数
+	readID   uint64        // 积压读取位置所在的段
+	readPos  int64         // 积压读取位置在段内的偏移
+	reader   *bufio.Reader // 读取 readID 段的 reader，nil 表示下次读取时打开
+	readFile *os.File
+	peeked   *spooledEntry // 已读出但缓冲区已满、未能读入的条目
+}
+
+// spooledEntry 从磁盘读出的条目
+type spooledEntry struct {
+	entry    LogEntry
+	seg      uint64
+	replayed bool // 上次运行遗留的条目
+}
+
+// openSpool 打开磁盘队列，目录中尚未确认的条目作为积压，由 load 逐步读入
+func openSpool(dir string, maxBytes, segmentBytes int64) (*spool, error) {
+	if maxBytes <= 0 {
+		maxBytes = defaultSpoolMaxBytes
+	}
+	if segmentBytes <= 0 {
+		segmentBytes = defaultSpoolSegmentBytes
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create spool dir: %w", err)
+	}
+
+	s := &spool{
+		dir:          dir,
+		maxBytes:     maxBytes,
+		segmentBytes: segmentBytes,
+		segments:     make(map[uint64]*spoolSegment),
+		nextID:       1,
+	}
+
+	ids, err := s.listSegments()
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range ids {
+		count, size, err := s.countSegment(id)
+		if err != nil {
+			return nil, err
+		}
+		s.nextID = id + 1
+		if count == 0 {
+			os.Remove(s.path(id))
+			continue
+		}
+		s.segments[id] = &spoolSegment{size: size, pending: count, sealed: true}
+		s.total += size
+		if s.backlog == 0 {
+			s.readID = id
+		}
+		s.backlog += count
+	}
+	s.replay = s.backlog
+	return s, nil
+}
+
+// path 返回段文件路径
+func (s *spool) path(id uint64) string {
+	return filepath.Join(s.dir, fmt.Sprintf("%020d%s", id, spoolSegmentExt))
+}
+
+// listSegments 返回目录中的段文件编号（升序）
+func (s *spool) listSegments() ([]uint64, error) {
+	files, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read spool dir: %w", err)
+	}
+	var ids []uint64
+	for _, f := range files {
+		name := f.Name()
+		if f.IsDir() || !strings.HasSuffix(name, spoolSegmentExt) {
+			continue
+		}
+		id, err := strconv.ParseUint(strings.TrimSuffix(name, spoolSegmentExt), 10, 64)
+		if err != nil || id == 0 {
+			continue
+		}
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids, nil
+}
+
+// countSegment 统计段文件中的有效条目数和字节数，进程崩溃导致的不完整行不计入
+func (s *spool) countSegment(id uint64) (int, int64, error) {
+	file, err := os.Open(s.path(id))
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to open spool segment: %w", err)
+	}
+	defer file.Close()
+
+	var count int
+	var size int64
+	reader := bufio.NewReader(file)
+	for {
+		line, err := reader.ReadBytes('\n')
+		size += int64(len(line))
+		if _, ok := parseSpoolLine(line); ok {
+			count++
+		}
+		if err != nil {
+			break
+		}
+	}
+	return count, size, nil
+}
+
+// parseSpoolLine 解析段文件中的一行，不完整或无法解析的行返回 false
+func parseSpoolLine(line []byte) (LogEntry, bool) {
+	var entry LogEntry
+	if len(line) == 0 || line[len(line)-1] != '\n' {
+		return entry, false
+	}
+	return entry, json.Unmarshal(line, &entry) == nil
+}
+
+// append 将条目写入当前段，返回段编号；没有积压时调用 admit 尝试放入缓冲区，
+// 未放入的条目进入积压，由 load 稍后读入。超过 SpoolMaxBytes 时返回 errSpoolFull
+func (s *spool) append(entry LogEntry, admit func(seg uint64) bool) (uint64, error) {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return 0, fmt.Errorf("failed to marshal spool entry: %w", err)
+	}
+	line = append(line, '\n')
+	size := int64(len(line))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.total+size > s.maxBytes {
+		return 0, errSpoolFull
+	}
+	if s.active != nil && s.segments[s.activeID].size+size > s.segmentBytes {
+		s.rotate()
+	}
+	if s.active == nil {
+		if err := s.create(); err != nil {
+			return 0, err
+		}
+	}
+
+	if _, err := s.active.Write(line); err != nil {
+		return 0, fmt.Errorf("failed to write spool segment: %w", err)
+	}
+	seg := s.segments[s.activeID]
+	offset := seg.size
+	seg.size += size
+	seg.pending++
+	s.total += size
+
+	if s.backlog == 0 {
+		if admit(s.activeID) {
+			return s.activeID, nil
+		}
+		s.closeReader()
+		s.readID = s.activeID
+		s.readPos = offset
+	}
+	s.backlog++
+	return s.activeID, nil
+}
+
+// load 按写入顺序读出积压的条目交给 admit，直到积压读完或 admit 返回 false（缓冲区已满），返回读入的条目数
+func (s *spool) load(admit func(item spooledEntry) bool) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	loaded := 0
+	for s.backlog > 0 {
+		item := s.peeked
+		if item == nil {
+			next, err := s.next()
+			if err != nil {
+				return loaded, err
+			}
+			item = &next
+		}
+		if !admit(*item) {
+			s.peeked = item
+			break
+		}
+		s.peeked = nil
+		s.backlog--
+		if s.replay > 0 {
+			s.replay--
+		}
+		loaded++
+	}
+	if s.backlog == 0 {
+		s.closeReader()
+	}
+	return loaded, nil
+}
+
+// next 读出积压中的下一条日志，当前段读完后转到下一个段，调用时需持有锁且 backlog > 0
+func (s *spool) next() (spooledEntry, error) {
+	for {
+		if s.reader == nil {
+			file, err := os.Open(s.path(s.readID))
+			if os.IsNotExist(err) {
+				// 段内条目已全部读入并确认，段文件已删除
+				if nextID, ok := s.following(s.readID); ok {
+					s.readID, s.readPos = nextID, 0
+					continue
+				}
+			}
+			if err != nil {
+				return spooledEntry{}, fmt.Errorf("failed to open spool segment: %w", err)
+			}
+			if _, err := file.Seek(s.readPos, io.SeekStart); err != nil {
+				file.Close()
+				return spooledEntry{}, fmt.Errorf("failed to seek spool segment: %w", err)
+			}
+			s.readFile = file
+			s.reader = bufio.NewReader(file)
+		}
+
+		line, err := s.reader.ReadBytes('\n')
+		s.readPos += int64(len(line))
+		if entry, ok := parseSpoolLine(line); ok {
+			return spooledEntry{entry: entry, seg: s.readID, replayed: s.replay > 0}, nil
+		}
+		if err == nil {
+			continue // 无法解析的行
+		}
+		if err != io.EOF {
+			return spooledEntry{}, fmt.Errorf("failed to read spool segment: %w", err)
+		}
+
+		// 段已读完，转到下一个段；积压中的条目都已写入磁盘，当前段不会是仍在写入的段
+		nextID, ok := s.following(s.readID)
+		if !ok {
+			return spooledEntry{}, fmt.Errorf("spool segment %d is missing %d entries", s.readID, s.backlog)
+		}
+		s.closeReader()
+		s.readID = nextID
+		s.readPos = 0
+	}
+}
+
+// following 返回 id 之后的第一个段，调用时需持有锁
+func (s *spool) following(id uint64) (uint64, bool) {
+	var next uint64
+	for seg := range s.segments {
+		if seg > id && (next == 0 || seg < next) {
+			next = seg
+		}
+	}
+	return next, next != 0
+}
+
+// closeReader 关闭积压读取使用的段文件，调用时需持有锁
+func (s *spool) closeReader() {
+	if s.readFile != nil {
+		s.readFile.Close()
+	}
+	s.readFile = nil
+	s.reader = nil
+}
+
+// pendingBacklog 返回只保存在磁盘上、尚未读入缓冲区的条目数
+func (s *spool) pendingBacklog() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.backlog
+}
+
+// create 新建一个段文件作为当前段，调用时需持有锁
+func (s *spool) create() error {
+	id := s.nextID
+	file, err := os.OpenFile(s.path(id), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to create spool segment: %w", err)
+	}
+	s.nextID++
+	s.active = file
+	s.activeID = id
+	s.segments[id] = &spoolSegment{}
+	return nil
+}
+
+// rotate 封存当前段，调用时需持有锁
+func (s *spool) rotate() {
+	s.active.Close()
+	s.active = nil
+	seg := s.segments[s.activeID]
+	seg.sealed = true
+	if seg.pending == 0 {
+		s.remove(s.activeID)
+	}
+}
+
+// ack 确认条目已处理完成，段内条目全部确认后删除段文件
+func (s *spool) ack(segs ...uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, id := range segs {
+		seg, ok := s.segments[id]
+		if !ok {
+			continue
+		}
+		seg.pending--
+		if seg.pending > 0 {
+			continue
+		}
+		if id == s.activeID && s.active != nil {
+			s.rotate()
+		} else if seg.sealed {
+			s.remove(id)
+		}
+	}
+}
+
+// remove 删除段文件，调用时需持有锁
+func (s *spool) remove(id uint64) {
+	if id == s.readID {
+		s.closeReader()
+	}
+	if seg, ok := s.segments[id]; ok {
+		s.total -= seg.size
+		delete(s.segments, id)
+	}
+	os.Remove(s.path(id))
+}
+
+// close 关闭当前段，未确认的段保留在磁盘上供下次启动时重放
+func (s *spool) close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closeReader()
+	if s.active == nil {
+		return nil
+	}
+	err := s.active.Close()
+	s.active = nil
+	s.segments[s.activeID].sealed = true
+	return err
+}
@@ -0,0 +1,290 @@
+package writer
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testSink 测试用 Sink，down 为 true 时整批返回可重试错误
+type testSink struct {
+	mu      sync.Mutex
+	down    bool
+	written []LogEntry
+}
+
+func (s *testSink) WriteBatch(ctx context.Context, entries []LogEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.down {
+		return Retryable(errors.New("service unavailable"))
+	}
+	s.written = append(s.written, entries...)
+	return nil
+}
+
+func (s *testSink) setDown(down bool) {
+	s.mu.Lock()
+	s.down = down
+	s.mu.Unlock()
+}
+
+func (s *testSink) count() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.written)
+}
+
+// blockingSink 测试用 Sink，一直阻塞到 ctx 结束
+type blockingSink struct{}
+
+func (blockingSink) WriteBatch(ctx context.Context, entries []LogEntry) error {
+	<-ctx.Done()
+	return Retryable(ctx.Err())
+}
+
+// testSpoolConfig 返回使用 dir 作为磁盘队列、快速重试的配置
+func testSpoolConfig(dir string) *BatchConfig {
+	config := DefaultBatchConfig()
+	config.BufferSize = 4
+	config.FlushInterval = time.Hour
+	config.SpoolDir = dir
+	config.Retry = &RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond}
+	config.OnError = func(err error, entries int) {}
+	return config
+}
+
+// segmentFiles 返回目录中的段文件
+func segmentFiles(t *testing.T, dir string) []string {
+	t.Helper()
+	files, err := filepath.Glob(filepath.Join(dir, "*"+spoolSegmentExt))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return files
+}
+
+func TestSpoolKeepsEntriesWhenRetriesExhausted(t *testing.T) {
+	dir := t.TempDir()
+	sink := &testSink{down: true}
+	var letters int
+	config := testSpoolConfig(dir)
+	config.DeadLetter = DeadLetterFunc(func(letter DeadLetter) error {
+		letters++
+		return nil
+	})
+
+	w, err := NewBatchWriter(sink, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 10; i++ {
+		w.Info("message", Field("i", i))
+	}
+
+	var unsent *UnsentError
+	if err := w.Flush(context.Background()); !errors.As(err, &unsent) {
+		t.Fatalf("Flush() error = %v, want *UnsentError", err)
+	}
+	if files := segmentFiles(t, dir); len(files) == 0 {
+		t.Fatal("spool segments deleted after retries were exhausted")
+	}
+	if err := w.Close(); !errors.As(err, &unsent) || unsent.Unsent != 10 {
+		t.Fatalf("Close() error = %v, want 10 unsent entries", err)
+	}
+	if letters != 0 {
+		t.Fatalf("dead letters = %d, want 0 for spooled entries", letters)
+	}
+	if stats := w.Stats(); stats.Failed != 0 {
+		t.Fatalf("Stats().Failed = %d, want 0", stats.Failed)
+	}
+
+	// 恢复后重新打开，磁盘上的日志全部写入并删除段文件
+	sink.setDown(false)
+	w, err = NewBatchWriter(sink, testSpoolConfig(dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if n := sink.count(); n != 10 {
+		t.Fatalf("written = %d, want 10", n)
+	}
+	if files := segmentFiles(t, dir); len(files) != 0 {
+		t.Fatalf("segments left after successful replay: %v", files)
+	}
+}
+
+func TestSpoolRetriesKeptEntriesAfterRecovery(t *testing.T) {
+	sink := &testSink{down: true}
+	w, err := NewBatchWriter(sink, testSpoolConfig(t.TempDir()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	for i := 0; i < 6; i++ {
+		w.Info("message", Field("i", i))
+	}
+	if err := w.Flush(context.Background()); err == nil {
+		t.Fatal("Flush() error = nil while sink is down")
+	}
+	if depth := w.Stats().BufferDepth; depth != 6 {
+		t.Fatalf("BufferDepth = %d, want 6 kept entries", depth)
+	}
+
+	sink.setDown(false)
+	if err := w.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+	if n := sink.count(); n != 6 {
+		t.Fatalf("written = %d, want 6", n)
+	}
+}
+
+func TestSpoolDeadLettersEntriesNotOnDisk(t *testing.T) {
+	dir := t.TempDir()
+	var letters int
+	config := testSpoolConfig(dir)
+	config.SpoolMaxBytes = 300
+	config.DeadLetter = DeadLetterFunc(func(letter DeadLetter) error {
+		letters++
+		return nil
+	})
+
+	w, err := NewBatchWriter(blockingSink{}, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 10; i++ {
+		w.Info("message", Field("i", i))
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	var unsent *UnsentError
+	if err := w.CloseContext(ctx); !errors.As(err, &unsent) || unsent.Unsent != 10 {
+		t.Fatalf("CloseContext() error = %v, want 10 unsent entries", err)
+	}
+
+	// 重新打开后可重放的条目与死信合计应覆盖全部日志
+	sink := &testSink{}
+	w, err = NewBatchWriter(sink, testSpoolConfig(dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	replayed := sink.count()
+	if replayed == 0 || replayed == 10 {
+		t.Fatalf("replayed = %d, want some entries spooled and some kept in memory", replayed)
+	}
+	if replayed+letters != 10 {
+		t.Fatalf("replayed %d + dead letters %d, want 10", replayed, letters)
+	}
+}
+
+func TestSpoolStreamsBacklogWithinBufferLimit(t *testing.T) {
+	dir := t.TempDir()
+	sink := &testSink{down: true}
+	config := testSpoolConfig(dir)
+	config.MaxBufferedEntries = 8
+	config.SpoolSegmentBytes = 1024
+
+	w, err := NewBatchWriter(sink, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 50; i++ {
+		w.Info("message", Field("i", i))
+	}
+	if stats := w.Stats(); stats.Dropped != 0 || stats.BufferDepth > 8 {
+		t.Fatalf("Dropped = %d, BufferDepth = %d, want 0 and <= 8", stats.Dropped, stats.BufferDepth)
+	}
+	var unsent *UnsentError
+	if err := w.Close(); !errors.As(err, &unsent) || unsent.Unsent != 50 {
+		t.Fatalf("Close() error = %v, want 50 unsent entries", err)
+	}
+
+	// 重新打开时只读入缓冲区容量以内的条目，其余在发送后按顺序读入
+	sink.setDown(false)
+	w, err = NewBatchWriter(sink, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if depth := w.Stats().BufferDepth; depth > 8 {
+		t.Fatalf("BufferDepth after open = %d, want <= 8", depth)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if n := sink.count(); n != 50 {
+		t.Fatalf("written = %d, want 50", n)
+	}
+	for i, entry := range sink.written {
+		if got := entry.Fields["i"]; got != float64(i) {
+			t.Fatalf("written[%d].i = %v, want %d", i, got, i)
+		}
+	}
+	if files := segmentFiles(t, dir); len(files) != 0 {
+		t.Fatalf("segments left after successful replay: %v", files)
+	}
+}
+
+// rejectSink 测试用 Sink，整批返回不可重试的错误
+type rejectSink struct {
+	mu    sync.Mutex
+	calls int
+}
+
+func (s *rejectSink) WriteBatch(ctx context.Context, entries []LogEntry) error {
+	s.mu.Lock()
+	s.calls++
+	s.mu.Unlock()
+	return errors.New("mapper_parsing_exception")
+}
+
+func TestSpoolDeadLettersPermanentFailures(t *testing.T) {
+	dir := t.TempDir()
+	sink := &rejectSink{}
+	var letters int
+	config := testSpoolConfig(dir)
+	config.DeadLetter = DeadLetterFunc(func(letter DeadLetter) error {
+		letters++
+		return nil
+	})
+
+	w, err := NewBatchWriter(sink, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		w.Info("message", Field("i", i))
+	}
+	if err := w.Flush(context.Background()); err == nil {
+		t.Fatal("Flush() error = nil, want the permanent failure")
+	}
+	if err := w.Flush(context.Background()); err != nil {
+		t.Fatalf("second Flush() error = %v, want nil after the batch was dead-lettered", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	if sink.calls != 1 {
+		t.Fatalf("sink calls = %d, want 1", sink.calls)
+	}
+	if letters != 3 {
+		t.Fatalf("dead letters = %d, want 3", letters)
+	}
+	if stats := w.Stats(); stats.Failed != 3 || stats.BufferDepth != 0 {
+		t.Fatalf("Failed = %d, BufferDepth = %d, want 3 and 0", stats.Failed, stats.BufferDepth)
+	}
+	if files := segmentFiles(t, dir); len(files) != 0 {
+		t.Fatalf("segments left after permanent failure: %v", files)
+	}
+}
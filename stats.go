@@ -0,0 +1,274 @@
+package writer
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// latencySamples 用于计算刷新耗时分位数的样本数
+const latencySamples = 1024
+
+// FlushDurationBuckets 刷新耗时直方图的桶上界（秒）
+var FlushDurationBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+
+// Stats 写入器运行统计
+type Stats struct {
+	Accepted      uint64       `json:"accepted"`                  // 进入缓冲区的条目数
+	Flushed       uint64       `json:"flushed"`                   // 成功写入的条目数
+	Failed        uint64       `json:"failed"`                    // 重试耗尽或被永久拒绝的条目数
+	Dropped       uint64       `json:"dropped"`                   // 因缓冲区溢出被丢弃的条目数
+	Retried       uint64       `json:"retried"`                   // 重新发送的条目数（同一条目每次重试计一次）
+	BufferDepth   int          `json:"buffer_depth"`              // 当前缓冲区中的条目数
+	BytesSent     uint64       `json:"bytes_sent"`                // 实际发送到后端的字节数（含重试，开启压缩时为压缩后大小）
+	BytesRaw      uint64       `json:"bytes_raw"`                 // 压缩前的字节数（含重试）
+	Flushes       uint64       `json:"flushes"`                   // 刷新次数
+	LastFlushTime time.Time    `json:"last_flush_time"`           // 最近一次刷新完成的时间
+	LastError     string       `json:"last_error,omitempty"`      // 最近一次刷新错误
+	LastErrorTime time.Time    `json:"last_error_time,omitempty"` // 最近一次刷新错误的时间
+	FlushLatency  LatencyStats `json:"flush_latency"`             // 刷新耗时分位数（最近 1024 次）
+
+	FlushDuration Histogram             `json:"flush_duration"`   // 刷新耗时直方图（全部刷新）
+	Levels        map[string]LevelStats `json:"levels,omitempty"` // 按日志级别的计数
+
+	DeadLetterErrors uint64 `json:"dead_letter_errors"` // 写入死信目的地失败的条目数
+}
+
+// LevelStats 单个日志级别的计数
+type LevelStats struct {
+	Accepted uint64 `json:"accepted"`
+	Flushed  uint64 `json:"flushed"`
+	Failed   uint64 `json:"failed"`
+	Dropped  uint64 `json:"dropped"`
+}
+
+// Histogram 累积直方图，Buckets[i] 为耗时不超过 FlushDurationBuckets[i] 秒的次数
+type Histogram struct {
+	Buckets []uint64 `json:"buckets"`
+	Count   uint64   `json:"count"`
+	Sum     float64  `json:"sum"` // 秒
+}
+
+// LatencyStats 耗时分位数
+type LatencyStats struct {
+	P50 time.Duration `json:"p50"`
+	P90 time.Duration `json:"p90"`
+	P99 time.Duration `json:"p99"`
+	Max time.Duration `json:"max"`
+}
+
+// StatsProvider 可提供运行统计的写入器
+type StatsProvider interface {
+	Stats() Stats
+}
+
+// statsCollector 写入器统计收集器
+type statsCollector struct {
+	accepted atomic.Uint64
+	flushed  atomic.Uint64
+	failed   atomic.Uint64
+	retried  atomic.Uint64
+	flushes  atomic.Uint64
+
+	deadLetterErrors atomic.Uint64
+
+	mu            sync.Mutex
+	lastFlushTime time.Time
+	lastError     string
+	lastErrorTime time.Time
+	latencies     [latencySamples]time.Duration
+	latencyCount  int
+	latencyNext   int
+	buckets       []uint64
+	durationCount uint64
+	durationSum   float64
+	levels        map[string]*LevelStats
+}
+
+// level 返回指定级别的计数，调用时需持有锁
+func (s *statsCollector) level(level string) *LevelStats {
+	if s.levels == nil {
+		s.levels = make(map[string]*LevelStats)
+	}
+	ls, ok := s.levels[level]
+	if !ok {
+		ls = &LevelStats{}
+		s.levels[level] = ls
+	}
+	return ls
+}
+
+// recordAccepted 记录一条进入缓冲区的日志
+func (s *statsCollector) recordAccepted(level string) {
+	s.accepted.Add(1)
+	s.mu.Lock()
+	s.level(level).Accepted++
+	s.mu.Unlock()
+}
+
+// recordDropped 记录一条因缓冲区溢出被丢弃的日志
+func (s *statsCollector) recordDropped(entry LogEntry) {
+	s.mu.Lock()
+	s.level(entry.Level).Dropped++
+	s.mu.Unlock()
+}
+
+// recordFlush 记录一次刷新的结果，failed 为 entries 中最终写入失败的条目，
+// unsent 为因截止时间到达而未发送（将被重新放回缓冲区或转入死信）的条目
+func (s *statsCollector) recordFlush(latency time.Duration, entries, failed, unsent []LogEntry, err error) {
+	s.flushes.Add(1)
+	s.flushed.Add(uint64(len(entries) - len(failed) - len(unsent)))
+	s.failed.Add(uint64(len(failed)))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, entry := range entries {
+		s.level(entry.Level).Flushed++
+	}
+	for _, entry := range failed {
+		ls := s.level(entry.Level)
+		ls.Flushed--
+		ls.Failed++
+	}
+	for _, entry := range unsent {
+		s.level(entry.Level).Flushed--
+	}
+
+	if s.buckets == nil {
+		s.buckets = make([]uint64, len(FlushDurationBuckets))
+	}
+	seconds := latency.Seconds()
+	for i, bound := range FlushDurationBuckets {
+		if seconds <= bound {
+			s.buckets[i]++
+		}
+	}
+	s.durationCount++
+	s.durationSum += seconds
+
+	now := time.Now()
+	s.lastFlushTime = now
+	if err != nil {
+		s.lastError = err.Error()
+		s.lastErrorTime = now
+	}
+	s.latencies[s.latencyNext] = latency
+	s.latencyNext = (s.latencyNext + 1) % latencySamples
+	if s.latencyCount < latencySamples {
+		s.latencyCount++
+	}
+}
+
+// recordDeadLetterFailure 记录写入死信目的地失败的条目
+func (s *statsCollector) recordDeadLetterFailure(n int, err error) {
+	s.deadLetterErrors.Add(uint64(n))
+	s.mu.Lock()
+	s.lastError = err.Error()
+	s.lastErrorTime = time.Now()
+	s.mu.Unlock()
+}
+
+// snapshot 返回统计快照（不含缓冲区和发送字节数）
+func (s *statsCollector) snapshot() Stats {
+	stats := Stats{
+		Accepted: s.accepted.Load(),
+		Flushed:  s.flushed.Load(),
+		Failed:   s.failed.Load(),
+		Retried:  s.retried.Load(),
+		Flushes:  s.flushes.Load(),
+
+		DeadLetterErrors: s.deadLetterErrors.Load(),
+	}
+
+	s.mu.Lock()
+	stats.LastFlushTime = s.lastFlushTime
+	stats.LastError = s.lastError
+	stats.LastErrorTime = s.lastErrorTime
+	samples := make([]time.Duration, s.latencyCount)
+	copy(samples, s.latencies[:s.latencyCount])
+	stats.FlushDuration = Histogram{
+		Buckets: make([]uint64, len(FlushDurationBuckets)),
+		Count:   s.durationCount,
+		Sum:     s.durationSum,
+	}
+	copy(stats.FlushDuration.Buckets, s.buckets)
+	if len(s.levels) > 0 {
+		stats.Levels = make(map[string]LevelStats, len(s.levels))
+		for level, ls := range s.levels {
+			stats.Levels[level] = *ls
+		}
+	}
+	s.mu.Unlock()
+
+	stats.FlushLatency = computeLatencyStats(samples)
+	return stats
+}
+
+// computeLatencyStats 计算耗时分位数
+func computeLatencyStats(samples []time.Duration) LatencyStats {
+	if len(samples) == 0 {
+		return LatencyStats{}
+	}
+	sort.Slice(samples, func(i, j int) bool { return samples[i] < samples[j] })
+	percentile := func(p float64) time.Duration {
+		return samples[int(float64(len(samples)-1)*p)]
+	}
+	return LatencyStats{
+		P50: percentile(0.50),
+		P90: percentile(0.90),
+		P99: percentile(0.99),
+		Max: samples[len(samples)-1],
+	}
+}
+
+// mergeStats 合并多个写入器的统计：计数累加，时间取最新，耗时分位数取最大值
+func mergeStats(all []Stats) Stats {
+	merged := Stats{
+		FlushDuration: Histogram{Buckets: make([]uint64, len(FlushDurationBuckets))},
+	}
+	for _, s := range all {
+		merged.Accepted += s.Accepted
+		merged.Flushed += s.Flushed
+		merged.Failed += s.Failed
+		merged.Dropped += s.Dropped
+		merged.Retried += s.Retried
+		merged.DeadLetterErrors += s.DeadLetterErrors
+		merged.BufferDepth += s.BufferDepth
+		merged.BytesSent += s.BytesSent
+		merged.BytesRaw += s.BytesRaw
+		merged.Flushes += s.Flushes
+		if s.LastFlushTime.After(merged.LastFlushTime) {
+			merged.LastFlushTime = s.LastFlushTime
+		}
+		if s.LastErrorTime.After(merged.LastErrorTime) {
+			merged.LastError = s.LastError
+			merged.LastErrorTime = s.LastErrorTime
+		}
+		merged.FlushLatency.P50 = max(merged.FlushLatency.P50, s.FlushLatency.P50)
+		merged.FlushLatency.P90 = max(merged.FlushLatency.P90, s.FlushLatency.P90)
+		merged.FlushLatency.P99 = max(merged.FlushLatency.P99, s.FlushLatency.P99)
+		merged.FlushLatency.Max = max(merged.FlushLatency.Max, s.FlushLatency.Max)
+
+		for i, n := range s.FlushDuration.Buckets {
+			if i < len(merged.FlushDuration.Buckets) {
+				merged.FlushDuration.Buckets[i] += n
+			}
+		}
+		merged.FlushDuration.Count += s.FlushDuration.Count
+		merged.FlushDuration.Sum += s.FlushDuration.Sum
+
+		for level, ls := range s.Levels {
+			if merged.Levels == nil {
+				merged.Levels = make(map[string]LevelStats)
+			}
+			m := merged.Levels[level]
+			m.Accepted += ls.Accepted
+			m.Flushed += ls.Flushed
+			m.Failed += ls.Failed
+			m.Dropped += ls.Dropped
+			merged.Levels[level] = m
+		}
+	}
+	return merged
+}
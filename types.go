@@ -37,9 +37,48 @@ type LogEntry struct {
 	Duration  string                 `json:"duration,omitempty"`
 	Trace     string                 `json:"trace,omitempty"`
 	Span      string                 `json:"span,omitempty"`
+	Caller    string                 `json:"caller,omitempty"`
+	Seq       uint64                 `json:"seq,omitempty"`
 	Fields    map[string]interface{} `json:"fields,omitempty"`
+
+	// Metadata 服务名、环境、主机等静态字段，序列化时与内置字段平铺在文档顶层；
+	// 由 BatchWriter 在处理器之后附加，多条日志共享同一个 map，不应修改
+	Metadata map[string]interface{} `json:"-"`
+
+	// time 构建条目时记录的时间，Timestamp 为空时由写入器按各自的时区和精度格式化
+	time time.Time
 }
 
+// OverflowPolicy 缓冲区满时的处理策略
+type OverflowPolicy string
+
+const (
+	// OverflowDropNewest 丢弃新写入的日志（默认）
+	OverflowDropNewest OverflowPolicy = "drop_newest"
+	// OverflowDropOldest 丢弃缓冲区中最早的日志
+	OverflowDropOldest OverflowPolicy = "drop_oldest"
+	// OverflowBlock 阻塞调用方直到有空间，超过 BlockTimeout 后丢弃新日志
+	OverflowBlock OverflowPolicy = "block"
+	// OverflowDropByLevel 优先丢弃低级别日志，尽量保留 error 及以上级别
+	OverflowDropByLevel OverflowPolicy = "drop_by_level"
+)
+
+// OversizedPolicy 单条日志超过 MaxDocumentBytes 时的处理策略
+type OversizedPolicy string
+
+const (
+	// OversizedTruncate 截断 content 并移除 fields，附加 _truncated 标记字段（默认）
+	OversizedTruncate OversizedPolicy = "truncate"
+	// OversizedReject 拒绝该条日志，交给 OnItemFailure 和死信目的地
+	OversizedReject OversizedPolicy = "reject"
+)
+
+const (
+	defaultMaxBufferedEntries = 10000
+	defaultBlockTimeout       = 100 * time.Millisecond
+	defaultMaxBulkBytes       = 10 << 20
+)
+
 // Config Elasticsearch Writer 配置
 type Config struct {
 	Addresses     []string      `json:"addresses"`
@@ -51,6 +90,74 @@ type Config struct {
 	FlushInterval time.Duration `json:"flush_interval"`
 	EnableSSL     bool          `json:"enable_ssl,omitempty"`
 	SkipSSLVerify bool          `json:"skip_ssl_verify,omitempty"`
+	MinLevel      Level         `json:"min_level,omitempty"` // 最低写入级别，低于此级别的日志直接丢弃
+	AtomicLevel   *AtomicLevel  `json:"-"`                   // 可在运行时修改的最低级别，设置后忽略 MinLevel
+	Processors    []Processor   `json:"-"`                   // 日志进入缓冲区前依次执行的处理器
+
+	MaxBufferedEntries int            `json:"max_buffered_entries,omitempty"`
+	MaxBufferedBytes   int64          `json:"max_buffered_bytes,omitempty"`
+	OverflowPolicy     OverflowPolicy `json:"overflow_policy,omitempty"`
+	BlockTimeout       time.Duration  `json:"block_timeout,omitempty"`
+
+	Retry *RetryPolicy `json:"retry,omitempty"`
+
+	// OnItemFailure 单条日志被 ES 拒绝（如 mapper_parsing_exception）或重试耗尽时调用，
+	// err 为 *BulkItemError，包含 ES 返回的错误原因
+	OnItemFailure func(entry LogEntry, err error) `json:"-"`
+
+	// DeadLetter 重试耗尽或被永久拒绝的日志的去处，nil 表示直接丢弃
+	DeadLetter DeadLetterSink `json:"-"`
+
+	// OnError 后台刷新失败时调用，entries 为本次未能写入的条目数；
+	// 未设置时错误会限频输出到 stderr
+	OnError func(err error, entries int) `json:"-"`
+
+	// FlushWorkers 并发发送 bulk 请求的 worker 数；大于 1 时批次之间不保证顺序
+	FlushWorkers int `json:"flush_workers,omitempty"`
+	// MaxInFlightBatches 已封装但尚未发送完成的批次上限，用于限制内存占用
+	MaxInFlightBatches int `json:"max_in_flight_batches,omitempty"`
+
+	// MaxBulkBytes 单个 bulk 请求体的字节上限，超出时拆分为多个请求
+	MaxBulkBytes int `json:"max_bulk_bytes,omitempty"`
+	// MaxDocumentBytes 单条日志序列化后的字节上限，0 表示不限制
+	MaxDocumentBytes int `json:"max_document_bytes,omitempty"`
+	// OversizedPolicy 单条日志超过 MaxDocumentBytes 时的处理策略
+	OversizedPolicy OversizedPolicy `json:"oversized_policy,omitempty"`
+
+	// CompressRequestBody 是否对 bulk 请求体进行 gzip 压缩
+	CompressRequestBody bool `json:"compress_request_body,omitempty"`
+	// CompressionLevel gzip 压缩级别（1~9，-2 为 HuffmanOnly），0 表示默认级别
+	CompressionLevel int `json:"compression_level,omitempty"`
+
+	// SpoolDir 磁盘队列目录，设置后日志先写入磁盘，写入 ES 成功后才删除，重启时自动重放；为空表示不启用
+	SpoolDir string `json:"spool_dir,omitempty"`
+	// SpoolMaxBytes 磁盘队列的字节上限，超出时新日志仅保存在内存中，0 表示 1GB
+	SpoolMaxBytes int64 `json:"spool_max_bytes,omitempty"`
+	// SpoolSegmentBytes 单个段文件的字节上限，0 表示 16MB
+	SpoolSegmentBytes int64 `json:"spool_segment_bytes,omitempty"`
+
+	// Service、Environment、Version 作为顶层字段 service、env、version 写入每条日志，为空时不写入
+	Service     string `json:"service,omitempty"`
+	Environment string `json:"environment,omitempty"`
+	Version     string `json:"version,omitempty"`
+	// StaticFields 写入每条日志顶层的静态字段，不能与 @timestamp、level 等内置字段同名
+	StaticFields map[string]interface{} `json:"static_fields,omitempty"`
+	// RuntimeInfo 是否自动附加主机名（host）、进程号（pid）和 Go 构建信息（build）
+	RuntimeInfo bool `json:"runtime_info,omitempty"`
+
+	// DisableCaller 不记录调用位置（caller），可省去每条日志获取调用栈的开销
+	DisableCaller bool `json:"disable_caller,omitempty"`
+	// CallerSkip 记录调用位置时额外跳过的栈帧数，用于自定义的日志封装函数
+	CallerSkip int `json:"caller_skip,omitempty"`
+
+	// Clock 生成时间戳的时钟，nil 使用系统时钟；测试中可注入固定时钟
+	Clock Clock `json:"-"`
+	// UTC 时间戳使用 UTC 时区，默认使用本地时区
+	UTC bool `json:"utc,omitempty"`
+	// TimestampPrecision 时间戳精度（如 time.Millisecond），0 表示纳秒；小数位数固定
+	TimestampPrecision time.Duration `json:"timestamp_precision,omitempty"`
+	// Sequence 是否为每条日志分配进程内单调递增的序号（seq），同一时刻的日志可按序号排序
+	Sequence bool `json:"sequence,omitempty"`
 }
 
 // PostgresConfig Postgresql Writer 配置
@@ -59,6 +166,38 @@ type PostgresConfig struct {
 	TableName     string        `json:"table_name"`     // 表名
 	BufferSize    int           `json:"buffer_size"`    // 缓冲区大小
 	FlushInterval time.Duration `json:"flush_interval"` // 刷新间隔
+
+	MinLevel    Level        `json:"min_level,omitempty"` // 最低写入级别，低于此级别的日志直接丢弃
+	AtomicLevel *AtomicLevel `json:"-"`                   // 可在运行时修改的最低级别，设置后忽略 MinLevel
+	Processors  []Processor  `json:"-"`                   // 日志进入缓冲区前依次执行的处理器
+
+	MaxBufferedEntries int            `json:"max_buffered_entries,omitempty"` // 缓冲区最大条目数
+	MaxBufferedBytes   int64          `json:"max_buffered_bytes,omitempty"`   // 缓冲区最大字节数（估算值），0 表示不限制
+	OverflowPolicy     OverflowPolicy `json:"overflow_policy,omitempty"`      // 缓冲区满时的处理策略
+	BlockTimeout       time.Duration  `json:"block_timeout,omitempty"`        // OverflowBlock 策略下的最长等待时间
+
+	Retry      *RetryPolicy   `json:"retry,omitempty"` // 批量写入失败时的重试策略，nil 使用默认策略
+	DeadLetter DeadLetterSink `json:"-"`               // 重试耗尽或被永久拒绝的日志的去处，nil 表示直接丢弃
+
+	OnError func(err error, entries int) `json:"-"` // 刷新失败时调用，未设置时限频输出到 stderr
+
+	SpoolDir          string `json:"spool_dir,omitempty"`           // 磁盘队列目录，为空表示不启用
+	SpoolMaxBytes     int64  `json:"spool_max_bytes,omitempty"`     // 磁盘队列的字节上限，0 表示 1GB
+	SpoolSegmentBytes int64  `json:"spool_segment_bytes,omitempty"` // 单个段文件的字节上限，0 表示 16MB
+
+	Service      string                 `json:"service,omitempty"`       // 服务名，写入 metadata 列的 service
+	Environment  string                 `json:"environment,omitempty"`   // 环境，写入 metadata 列的 env
+	Version      string                 `json:"version,omitempty"`       // 版本，写入 metadata 列的 version
+	StaticFields map[string]interface{} `json:"static_fields,omitempty"` // 写入 metadata 列的静态字段
+	RuntimeInfo  bool                   `json:"runtime_info,omitempty"`  // 是否附加主机名、进程号和 Go 构建信息
+
+	DisableCaller bool `json:"disable_caller,omitempty"` // 不记录调用位置（caller 列）
+	CallerSkip    int  `json:"caller_skip,omitempty"`    // 记录调用位置时额外跳过的栈帧数
+
+	Clock              Clock         `json:"-"`                             // 生成时间戳的时钟，nil 使用系统时钟
+	UTC                bool          `json:"utc,omitempty"`                 // 时间戳使用 UTC 时区，默认使用本地时区
+	TimestampPrecision time.Duration `json:"timestamp_precision,omitempty"` // 时间戳精度，0 表示纳秒
+	Sequence           bool          `json:"sequence,omitempty"`            // 是否分配单调递增的序号（seq 列）
 }
 
 // DefaultConfig 返回默认配置
@@ -69,6 +208,16 @@ func DefaultConfig() *Config {
 		BufferSize:    100,
 		FlushInterval: 5 * time.Second,
 		EnableSSL:     false,
+
+		MaxBufferedEntries: defaultMaxBufferedEntries,
+		OverflowPolicy:     OverflowDropNewest,
+		BlockTimeout:       defaultBlockTimeout,
+		Retry:              DefaultRetryPolicy(),
+		FlushWorkers:       1,
+		MaxInFlightBatches: 2,
+		MaxBulkBytes:       defaultMaxBulkBytes,
+		MaxDocumentBytes:   1 << 20,
+		OversizedPolicy:    OversizedTruncate,
 	}
 }
 
@@ -78,5 +227,10 @@ func DefaultPostgresConfig() *PostgresConfig {
 		TableName:     "logs",
 		BufferSize:    100,
 		FlushInterval: 5 * time.Second,
+
+		MaxBufferedEntries: defaultMaxBufferedEntries,
+		OverflowPolicy:     OverflowDropNewest,
+		BlockTimeout:       defaultBlockTimeout,
+		Retry:              DefaultRetryPolicy(),
 	}
 }
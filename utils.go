@@ -3,8 +3,10 @@ package writer
 import (
 	"fmt"
 	"runtime"
+	"sort"
 	"strings"
 	"time"
+	"unicode/utf8"
 )
 
 // FormatContent 格式化内容为字符串
@@ -60,6 +62,32 @@ func ExtractFields(fields []FieldAccessor) (trace, span, duration string) {
 	return extractFields(fields)
 }
 
+// fieldOrder 返回 values 中的字段名：先按 fields 的原始顺序，处理器新增的字段按字母顺序排在最后
+func fieldOrder[T FieldAccessor](fields []T, values map[string]interface{}) []string {
+	keys := make([]string, 0, len(values))
+	seen := make(map[string]bool, len(values))
+	for _, field := range fields {
+		key := field.GetKey()
+		if _, ok := values[key]; ok && !seen[key] {
+			keys = append(keys, key)
+			seen[key] = true
+		}
+	}
+	var extra []string
+	for key := range values {
+		if !seen[key] {
+			extra = append(extra, key)
+		}
+	}
+	sort.Strings(extra)
+	return append(keys, extra...)
+}
+
+// FieldOrder 导出的字段排序函数，用于按原始顺序输出经过处理器的字段
+func FieldOrder(fields []FieldAccessor, values map[string]interface{}) []string {
+	return fieldOrder(fields, values)
+}
+
 // getCaller 获取调用者信息
 func GetCaller(skip int) string {
 	_, file, line, ok := runtime.Caller(skip + 1)
@@ -72,3 +100,37 @@ func GetCaller(skip int) string {
 	}
 	return fmt.Sprintf("%s:%d", file, line)
 }
+
+// truncateEntry 截断超长日志：移除 fields，截断 content，并附加 _truncated 标记字段
+func truncateEntry(entry LogEntry, size, limit int) LogEntry {
+	truncated := entry
+	truncated.Fields = map[string]interface{}{
+		"_truncated":      true,
+		"_original_bytes": size,
+	}
+
+	// 预留 content 以外的字段以及 JSON 转义的开销
+	overhead := estimateEntrySize(LogEntry{
+		Timestamp: entry.Timestamp,
+		Level:     entry.Level,
+		Duration:  entry.Duration,
+		Trace:     entry.Trace,
+		Span:      entry.Span,
+		Caller:    entry.Caller,
+		Fields:    truncated.Fields,
+		Metadata:  entry.Metadata,
+	})
+	budget := (limit - int(overhead)) / 2
+	if budget < 0 {
+		budget = 0
+	}
+	if len(entry.Content) > budget {
+		// 避免在多字节字符中间截断
+		cut := budget
+		for cut > 0 && !utf8.RuneStart(entry.Content[cut]) {
+			cut--
+		}
+		truncated.Content = entry.Content[:cut]
+	}
+	return truncated
+}
@@ -0,0 +1,154 @@
+package writer
+
+import "context"
+
+// BoundWriter 绑定了字段的子 Writer，绑定字段会合并到每条日志中，调用时传入的同名字段优先。
+// 子 Writer 很轻量，可以按请求创建，无需关闭
+type BoundWriter struct {
+	writer Writer
+	fields []LogField
+}
+
+// With 返回一个绑定了 fields 的子 Writer，w 可以是任意 Writer（包括 MultiWriter）
+func With(w Writer, fields ...LogField) *BoundWriter {
+	if b, ok := w.(*BoundWriter); ok {
+		return b.With(fields...)
+	}
+	return &BoundWriter{writer: w, fields: mergeFields(nil, fields)}
+}
+
+// With 返回一个在当前绑定字段基础上追加 fields 的子 Writer，同名字段以 fields 为准
+func (b *BoundWriter) With(fields ...LogField) *BoundWriter {
+	return &BoundWriter{writer: b.writer, fields: mergeFields(b.fields, fields)}
+}
+
+// Fields 返回绑定的字段
+func (b *BoundWriter) Fields() []LogField {
+	return b.fields[:len(b.fields):len(b.fields)]
+}
+
+// mergeFields 合并字段：保留 bound 中未被 fields 覆盖的字段，再追加 fields
+func mergeFields(bound, fields []LogField) []LogField {
+	if len(fields) == 0 {
+		return bound[:len(bound):len(bound)]
+	}
+	if len(bound) == 0 {
+		return append([]LogField(nil), fields...)
+	}
+	override := make(map[string]bool, len(fields))
+	for _, f := range fields {
+		override[f.Key] = true
+	}
+	merged := make([]LogField, 0, len(bound)+len(fields))
+	for _, f := range bound {
+		if !override[f.Key] {
+			merged = append(merged, f)
+		}
+	}
+	return append(merged, fields...)
+}
+
+// Log 写入日志
+func (b *BoundWriter) Log(level string, content any, fields ...LogField) {
+	b.writer.Log(level, content, mergeFields(b.fields, fields)...)
+}
+
+// Info 写入 info 级别日志
+func (b *BoundWriter) Info(content any, fields ...LogField) {
+	b.writer.Info(content, mergeFields(b.fields, fields)...)
+}
+
+// Error 写入 error 级别日志
+func (b *BoundWriter) Error(content any, fields ...LogField) {
+	b.writer.Error(content, mergeFields(b.fields, fields)...)
+}
+
+// Debug 写入 debug 级别日志
+func (b *BoundWriter) Debug(content any, fields ...LogField) {
+	b.writer.Debug(content, mergeFields(b.fields, fields)...)
+}
+
+// Warn 写入 warn 级别日志
+func (b *BoundWriter) Warn(content any, fields ...LogField) {
+	b.writer.Warn(content, mergeFields(b.fields, fields)...)
+}
+
+// LogCtx 使用 ctx 写入日志
+func (b *BoundWriter) LogCtx(ctx context.Context, level string, content any, fields ...LogField) {
+	LogCtx(ctx, b.writer, level, content, mergeFields(b.fields, fields)...)
+}
+
+// InfoCtx 使用 ctx 写入 info 级别日志
+func (b *BoundWriter) InfoCtx(ctx context.Context, content any, fields ...LogField) {
+	b.LogCtx(ctx, "info", content, fields...)
+}
+
+// ErrorCtx 使用 ctx 写入 error 级别日志
+func (b *BoundWriter) ErrorCtx(ctx context.Context, content any, fields ...LogField) {
+	b.LogCtx(ctx, "error", content, fields...)
+}
+
+// DebugCtx 使用 ctx 写入 debug 级别日志
+func (b *BoundWriter) DebugCtx(ctx context.Context, content any, fields ...LogField) {
+	b.LogCtx(ctx, "debug", content, fields...)
+}
+
+// WarnCtx 使用 ctx 写入 warn 级别日志
+func (b *BoundWriter) WarnCtx(ctx context.Context, content any, fields ...LogField) {
+	b.LogCtx(ctx, "warn", content, fields...)
+}
+
+// WriteEntry 将绑定字段合并到已构建好的日志条目后写入底层 Writer，条目中已有的同名字段优先，实现 EntryWriter 接口
+func (b *BoundWriter) WriteEntry(entry LogEntry) {
+	if len(b.fields) > 0 {
+		fields := make(map[string]interface{}, len(b.fields)+len(entry.Fields))
+		for _, f := range b.fields {
+			fields[f.Key] = f.Value
+		}
+		for key, value := range entry.Fields {
+			fields[key] = value
+		}
+		entry.Fields = fields
+		// trace/span/duration 分别补全，条目中已有的值优先
+		trace, span, duration := extractFields(b.fields)
+		if entry.Trace == "" {
+			entry.Trace = trace
+		}
+		if entry.Span == "" {
+			entry.Span = span
+		}
+		if entry.Duration == "" {
+			entry.Duration = duration
+		}
+	}
+	WriteEntry(b.writer, entry)
+}
+
+// Close 关闭底层 Writer，会影响共享该 Writer 的所有子 Writer，通常只应关闭根 Writer
+func (b *BoundWriter) Close() error {
+	return b.writer.Close()
+}
+
+// Flush 刷新底层 Writer（如果支持）
+func (b *BoundWriter) Flush(ctx context.Context) error {
+	if f, ok := b.writer.(Flusher); ok {
+		return f.Flush(ctx)
+	}
+	return nil
+}
+
+// CloseContext 带截止时间关闭底层 Writer
+func (b *BoundWriter) CloseContext(ctx context.Context) error {
+	if c, ok := b.writer.(ContextCloser); ok {
+		return c.CloseContext(ctx)
+	}
+	return b.writer.Close()
+}
+
+// Stats 返回底层 Writer 的统计（如果支持）
+func (b *BoundWriter) Stats() Stats {
+	if p, ok := b.writer.(StatsProvider); ok {
+		return p.Stats()
+	}
+	return Stats{}
+}
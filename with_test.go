@@ -0,0 +1,31 @@
+package writer
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBoundWriterWriteEntryFillsSpecialFieldsSeparately(t *testing.T) {
+	sink := &testSink{}
+	w, err := NewBatchWriter(sink, &BatchConfig{FlushInterval: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bound := With(w, Field("trace", "bound-trace"), Field("span", "bound-span"), Field("duration", time.Second))
+	bound.WriteEntry(LogEntry{Level: "info", Content: "message", Duration: "5ms"})
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if n := sink.count(); n != 1 {
+		t.Fatalf("written = %d, want 1", n)
+	}
+	entry := sink.written[0]
+	if entry.Trace != "bound-trace" || entry.Span != "bound-span" {
+		t.Fatalf("trace/span = %q/%q, want the bound values", entry.Trace, entry.Span)
+	}
+	if entry.Duration != "5ms" {
+		t.Fatalf("duration = %q, want the entry's own value 5ms", entry.Duration)
+	}
+}
@@ -2,10 +2,13 @@ package writer
 
 import (
 	"bytes"
+	"compress/gzip"
 	"context"
 	"encoding/json"
 	"fmt"
+	"net/http"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/elastic/go-elasticsearch/v8"
@@ -14,16 +17,22 @@ import (
 
 // ElasticsearchWriter 核心写入器（不依赖 go-zero）
 type ElasticsearchWriter struct {
-	client     *elasticsearch.Client
-	config     *Config
-	buffer     []LogEntry
-	bufferMu   sync.Mutex
-	bufferSize int
-	indexName  string
-	ctx        context.Context
-	cancel     context.CancelFunc
-	wg         sync.WaitGroup
-	flushChan  chan struct{}
+	*BatchWriter
+	sink *esSink
+}
+
+// esSink 通过 Bulk API 写入 Elasticsearch 的 Sink
+type esSink struct {
+	client    *elasticsearch.Client
+	config    *Config
+	retry     *RetryPolicy // 仅用于判断状态码是否可重试
+	indexName string
+
+	bytesRaw  atomic.Uint64
+	bytesSent atomic.Uint64
+
+	gzipPool sync.Pool // 复用 gzip.Writer，避免每次发送都分配
+	bufPool  sync.Pool // 复用压缩输出 buffer
 }
 
 // NewElasticsearchWriter 创建一个新的 Elasticsearch Writer
@@ -38,15 +47,17 @@ func NewElasticsearchWriter(config *Config) (*ElasticsearchWriter, error) {
 	if config.IndexPrefix == "" {
 		config.IndexPrefix = "go-zero-logs"
 	}
-	if config.BufferSize <= 0 {
-		config.BufferSize = 100
+	if config.CompressionLevel == 0 || config.CompressionLevel < gzip.HuffmanOnly || config.CompressionLevel > gzip.BestCompression {
+		config.CompressionLevel = gzip.DefaultCompression
 	}
-	if config.FlushInterval <= 0 {
-		config.FlushInterval = 5 * time.Second
+	if config.MaxBulkBytes <= 0 {
+		config.MaxBulkBytes = defaultMaxBulkBytes
 	}
 
 	esConfig := elasticsearch.Config{
 		Addresses: config.Addresses,
+		// 重试由 RetryPolicy 统一控制，避免与客户端内置重试叠加
+		DisableRetry: true,
 	}
 
 	if config.APIKey != "" {
@@ -61,67 +72,66 @@ func NewElasticsearchWriter(config *Config) (*ElasticsearchWriter, error) {
 		return nil, fmt.Errorf("failed to create elasticsearch client: %w", err)
 	}
 
-	ctx, cancel := context.WithCancel(context.Background())
-	w := &ElasticsearchWriter{
-		client:     client,
-		config:     config,
-		buffer:     make([]LogEntry, 0, config.BufferSize),
-		bufferSize: config.BufferSize,
-		indexName:  config.IndexPrefix,
-		ctx:        ctx,
-		cancel:     cancel,
-		flushChan:  make(chan struct{}, 1),
+	sink := &esSink{
+		client:    client,
+		config:    config,
+		retry:     config.Retry.normalize(),
+		indexName: config.IndexPrefix,
 	}
-
-	w.wg.Add(1)
-	go w.flushLoop()
-
-	return w, nil
-}
-
-// log 内部日志方法，接收 caller 参数
-func (w *ElasticsearchWriter) log(level string, content any, fields ...LogField) {
-	trace, span, duration := extractFields(fields)
-	entry := LogEntry{
-		Timestamp: time.Now().Format(time.RFC3339),
-		Level:     level,
-		Content:   FormatContent(content),
-		Duration:  duration,
-		Trace:     trace,
-		Span:      span,
-		Fields:    convertFields(fields),
+	sink.gzipPool.New = func() any {
+		gz, _ := gzip.NewWriterLevel(nil, config.CompressionLevel)
+		return gz
+	}
+	sink.bufPool.New = func() any {
+		return new(bytes.Buffer)
 	}
-	w.AddEntry(entry)
-}
-
-// Log 写入日志（公开方法，供外部直接调用）
-func (w *ElasticsearchWriter) Log(level string, content any, fields ...LogField) {
-	w.log(level, content, fields...)
-}
-
-// Info 写入 info 级别日志
-func (w *ElasticsearchWriter) Info(content any, fields ...LogField) {
-	w.log("info", content, fields...)
-}
-
-// Error 写入 error 级别日志
-func (w *ElasticsearchWriter) Error(content any, fields ...LogField) {
-	w.log("error", content, fields...)
-}
 
-// Debug 写入 debug 级别日志
-func (w *ElasticsearchWriter) Debug(content any, fields ...LogField) {
-	w.log("debug", content, fields...)
+	bw, err := NewBatchWriter(sink, config.batchConfig())
+	if err != nil {
+		return nil, err
+	}
+	return &ElasticsearchWriter{BatchWriter: bw, sink: sink}, nil
 }
 
-// Warn 写入 warn 级别日志
-func (w *ElasticsearchWriter) Warn(content any, fields ...LogField) {
-	w.log("warn", content, fields...)
+// batchConfig 转换为 BatchWriter 配置
+func (c *Config) batchConfig() *BatchConfig {
+	return &BatchConfig{
+		Name:               "elasticsearch",
+		BufferSize:         c.BufferSize,
+		FlushInterval:      c.FlushInterval,
+		MinLevel:           c.MinLevel,
+		AtomicLevel:        c.AtomicLevel,
+		Processors:         c.Processors,
+		MaxBufferedEntries: c.MaxBufferedEntries,
+		MaxBufferedBytes:   c.MaxBufferedBytes,
+		OverflowPolicy:     c.OverflowPolicy,
+		BlockTimeout:       c.BlockTimeout,
+		Retry:              c.Retry,
+		FlushWorkers:       c.FlushWorkers,
+		MaxInFlightBatches: c.MaxInFlightBatches,
+		DeadLetter:         c.DeadLetter,
+		OnError:            c.OnError,
+		SpoolDir:           c.SpoolDir,
+		SpoolMaxBytes:      c.SpoolMaxBytes,
+		SpoolSegmentBytes:  c.SpoolSegmentBytes,
+		Service:            c.Service,
+		Environment:        c.Environment,
+		Version:            c.Version,
+		StaticFields:       c.StaticFields,
+		RuntimeInfo:        c.RuntimeInfo,
+		DisableCaller:      c.DisableCaller,
+		CallerSkip:         c.CallerSkip,
+		Clock:              c.Clock,
+		UTC:                c.UTC,
+		TimestampPrecision: c.TimestampPrecision,
+		Sequence:           c.Sequence,
+		OnItemFailure:      c.OnItemFailure,
+	}
 }
 
 // Ping 检查 Elasticsearch 连接是否正常
 func (w *ElasticsearchWriter) Ping(ctx context.Context) error {
-	res, err := w.client.Info()
+	res, err := w.sink.client.Info()
 	if err != nil {
 		return fmt.Errorf("failed to ping elasticsearch: %w", err)
 	}
@@ -134,113 +144,260 @@ func (w *ElasticsearchWriter) Ping(ctx context.Context) error {
 	return nil
 }
 
-// Close 关闭写入器
-func (w *ElasticsearchWriter) Close() error {
-	w.cancel()
-	w.wg.Wait()
-	return w.flush()
+// Target 返回当天的索引名，实现 TargetSink 接口
+func (s *esSink) Target() string {
+	return s.getIndexName()
 }
 
-// AddEntry 添加日志条目到缓冲区（导出供适配器使用）
-func (w *ElasticsearchWriter) AddEntry(entry LogEntry) {
-	w.bufferMu.Lock()
-	w.buffer = append(w.buffer, entry)
-	shouldFlush := len(w.buffer) >= w.bufferSize
-	w.bufferMu.Unlock()
-
-	if shouldFlush {
-		select {
-		case w.flushChan <- struct{}{}:
-		default:
-		}
-	}
+// SinkStats 返回发送字节数，实现 SinkStatsProvider 接口
+func (s *esSink) SinkStats() SinkStats {
+	return SinkStats{BytesRaw: s.bytesRaw.Load(), BytesSent: s.bytesSent.Load()}
 }
 
-// flush 刷新缓冲区
-func (w *ElasticsearchWriter) flush() error {
-	w.bufferMu.Lock()
-	if len(w.buffer) == 0 {
-		w.bufferMu.Unlock()
-		return nil
+// WriteBatch 将一批日志通过 Bulk API 写入，部分失败时返回 *BatchError
+func (s *esSink) WriteBatch(ctx context.Context, entries []LogEntry) error {
+	failures, permanent, err := s.bulk(ctx, s.getIndexName(), entries)
+	if err != nil {
+		return err
 	}
-
-	entries := make([]LogEntry, len(w.buffer))
-	copy(entries, w.buffer)
-	w.buffer = w.buffer[:0]
-	w.bufferMu.Unlock()
-
-	if len(entries) == 0 {
+	if len(failures) == 0 && len(permanent) == 0 {
 		return nil
 	}
+	be := &BatchError{}
+	for _, f := range failures {
+		be.Retry = append(be.Retry, ItemError{Index: f.index, Err: f.err})
+	}
+	for _, f := range permanent {
+		be.Failed = append(be.Failed, ItemError{Index: f.index, Err: f.err})
+	}
+	return be
+}
 
-	indexName := w.getIndexName()
+// bulk 将一批日志按 MaxBulkBytes 切分为多个 bulk 请求发送，解析每条文档的写入结果。
+// 分别返回以可重试错误失败的条目和被永久拒绝的条目。
+func (s *esSink) bulk(ctx context.Context, indexName string, entries []LogEntry) (failures, permanent []bulkItemFailure, err error) {
+	meta, err := json.Marshal(map[string]interface{}{
+		"index": map[string]interface{}{
+			"_index": indexName,
+		},
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal bulk meta: %w", err)
+	}
 
 	var buf bytes.Buffer
-	for _, entry := range entries {
-		meta := map[string]interface{}{
-			"index": map[string]interface{}{
-				"_index": indexName,
-			},
-		}
-		metaJSON, err := json.Marshal(meta)
-		if err != nil {
-			continue
+	chunk := make([]int, 0, len(entries))
+	sendChunk := func() {
+		if len(chunk) == 0 {
+			return
 		}
-		buf.Write(metaJSON)
-		buf.WriteByte('\n')
+		f, p := s.bulkChunk(ctx, indexName, buf.Bytes(), chunk)
+		failures = append(failures, f...)
+		permanent = append(permanent, p...)
+		buf.Reset()
+		chunk = chunk[:0]
+	}
 
-		docJSON, err := json.Marshal(entry)
-		if err != nil {
+	for i, entry := range entries {
+		docJSON, itemErr := s.marshalDocument(indexName, entry)
+		if itemErr != nil {
+			permanent = append(permanent, bulkItemFailure{index: i, err: itemErr})
 			continue
 		}
+		size := len(meta) + len(docJSON) + 2
+		if buf.Len() > 0 && buf.Len()+size > s.config.MaxBulkBytes {
+			sendChunk()
+		}
+		buf.Write(meta)
+		buf.WriteByte('\n')
 		buf.Write(docJSON)
 		buf.WriteByte('\n')
+		chunk = append(chunk, i)
 	}
+	sendChunk()
 
-	if buf.Len() == 0 {
-		return nil
+	return failures, permanent, nil
+}
+
+// bulkChunk 发送一个 bulk 请求，indices 为 body 中各文档在批次中的下标
+func (s *esSink) bulkChunk(ctx context.Context, indexName string, body []byte, indices []int) (failures, permanent []bulkItemFailure) {
+	// failAll 将整个请求的失败展开到每个条目
+	failAll := func(status int, errType, reason string, retry bool) {
+		for _, idx := range indices {
+			f := bulkItemFailure{index: idx, err: &BulkItemError{
+				Index:  indexName,
+				Status: status,
+				Type:   errType,
+				Reason: reason,
+			}}
+			if retry {
+				failures = append(failures, f)
+			} else {
+				permanent = append(permanent, f)
+			}
+		}
 	}
 
 	req := esapi.BulkRequest{
-		Body:    bytes.NewReader(buf.Bytes()),
+		Body:    bytes.NewReader(body),
 		Refresh: "false",
 	}
+	sent := len(body)
 
-	res, err := req.Do(context.Background(), w.client)
+	if s.config.CompressRequestBody {
+		compressed, err := s.compress(body)
+		if err != nil {
+			failAll(0, "compress_error", err.Error(), false)
+			return failures, permanent
+		}
+		defer s.bufPool.Put(compressed)
+		req.Body = bytes.NewReader(compressed.Bytes())
+		req.Header = http.Header{"Content-Encoding": []string{"gzip"}}
+		sent = compressed.Len()
+	}
+
+	res, err := req.Do(ctx, s.client)
 	if err != nil {
-		return fmt.Errorf("failed to execute bulk request: %w", err)
+		failAll(0, "transport_error", fmt.Sprintf("failed to execute bulk request: %v", err), true)
+		return failures, permanent
 	}
 	defer res.Body.Close()
+	s.bytesRaw.Add(uint64(len(body)))
+	s.bytesSent.Add(uint64(sent))
 
 	if res.IsError() {
-		return fmt.Errorf("elasticsearch error: %s", res.String())
+		failAll(res.StatusCode, "request_error", res.String(), s.retry.retryableStatus(res.StatusCode))
+		return failures, permanent
 	}
 
-	return nil
+	var br bulkResponse
+	if err := json.NewDecoder(res.Body).Decode(&br); err != nil {
+		failAll(res.StatusCode, "response_decode_error", err.Error(), false)
+		return failures, permanent
+	}
+	if !br.Errors {
+		return nil, nil
+	}
+
+	for i, item := range br.Items {
+		if i >= len(indices) {
+			break
+		}
+		for _, result := range item {
+			if result.Error == nil {
+				continue
+			}
+			f := bulkItemFailure{index: indices[i], err: &BulkItemError{
+				Index:  result.Index,
+				Status: result.Status,
+				Type:   result.Error.Type,
+				Reason: result.Error.Reason,
+			}}
+			if result.Status == 429 || result.Status >= 500 || s.retry.retryableStatus(result.Status) {
+				failures = append(failures, f)
+			} else {
+				permanent = append(permanent, f)
+			}
+		}
+	}
+
+	return failures, permanent
 }
 
-// getIndexName 获取索引名称（按日期）
-func (w *ElasticsearchWriter) getIndexName() string {
-	today := time.Now().Format("2006.01.02")
-	return fmt.Sprintf("%s-%s", w.indexName, today)
+// compress 使用池化的 gzip.Writer 压缩请求体，返回的 buffer 用完后需放回 bufPool
+func (s *esSink) compress(body []byte) (*bytes.Buffer, error) {
+	out := s.bufPool.Get().(*bytes.Buffer)
+	out.Reset()
+
+	gz := s.gzipPool.Get().(*gzip.Writer)
+	defer s.gzipPool.Put(gz)
+	gz.Reset(out)
+
+	if _, err := gz.Write(body); err != nil {
+		s.bufPool.Put(out)
+		return nil, fmt.Errorf("failed to gzip bulk body: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		s.bufPool.Put(out)
+		return nil, fmt.Errorf("failed to gzip bulk body: %w", err)
+	}
+	return out, nil
 }
 
-// flushLoop 刷新循环（后台 goroutine）
-func (w *ElasticsearchWriter) flushLoop() {
-	defer w.wg.Done()
+// marshalDocument 序列化单条日志，超过 MaxDocumentBytes 时按 OversizedPolicy 截断或拒绝
+func (s *esSink) marshalDocument(indexName string, entry LogEntry) ([]byte, *BulkItemError) {
+	docJSON, err := json.Marshal(entry)
+	if err != nil {
+		return nil, &BulkItemError{Index: indexName, Type: "marshal_error", Reason: err.Error()}
+	}
 
-	ticker := time.NewTicker(w.config.FlushInterval)
-	defer ticker.Stop()
+	limit := s.config.MaxDocumentBytes
+	if limit <= 0 || len(docJSON) <= limit {
+		return docJSON, nil
+	}
 
-	for {
-		select {
-		case <-w.ctx.Done():
-			w.flush()
-			return
-		case <-ticker.C:
-			w.flush()
-		case <-w.flushChan:
-			w.flush()
-		}
+	tooLarge := &BulkItemError{
+		Index:  indexName,
+		Status: 413,
+		Type:   "document_too_large",
+		Reason: fmt.Sprintf("document is %d bytes, exceeds limit of %d bytes", len(docJSON), limit),
+	}
+	if s.config.OversizedPolicy == OversizedReject {
+		return nil, tooLarge
+	}
+
+	truncated := truncateEntry(entry, len(docJSON), limit)
+	docJSON, err = json.Marshal(truncated)
+	if err != nil || len(docJSON) > limit {
+		return nil, tooLarge
+	}
+	return docJSON, nil
+}
+
+// BulkItemError Bulk 请求中单条文档写入失败的错误
+type BulkItemError struct {
+	Index  string // 目标索引
+	Status int    // 文档级别的 HTTP 状态码
+	Type   string // ES 错误类型，如 mapper_parsing_exception
+	Reason string // ES 返回的错误原因
+}
+
+func (e *BulkItemError) Error() string {
+	return fmt.Sprintf("elasticsearch bulk item failed: index=%s status=%d type=%s reason=%s",
+		e.Index, e.Status, e.Type, e.Reason)
+}
+
+// bulkItemFailure 写入失败的条目
+type bulkItemFailure struct {
+	index int // 条目在批次中的下标
+	err   *BulkItemError
+}
+
+// bulkResponse Bulk API 响应
+type bulkResponse struct {
+	Errors bool                          `json:"errors"`
+	Items  []map[string]bulkResponseItem `json:"items"`
+}
+
+// bulkResponseItem Bulk API 响应中的单条结果
+type bulkResponseItem struct {
+	Index  string `json:"_index"`
+	Status int    `json:"status"`
+	Error  *struct {
+		Type   string `json:"type"`
+		Reason string `json:"reason"`
+	} `json:"error,omitempty"`
+}
+
+// getIndexName 获取索引名称（按日期，使用配置的时钟和时区）
+func (s *esSink) getIndexName() string {
+	now := time.Now()
+	if s.config.Clock != nil {
+		now = s.config.Clock.Now()
+	}
+	if s.config.UTC {
+		now = now.UTC()
 	}
+	today := now.Format("2006.01.02")
+	return fmt.Sprintf("%s-%s", s.indexName, today)
 }
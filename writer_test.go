@@ -0,0 +1,210 @@
+package writer
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeES 模拟 Elasticsearch Bulk API，respond 根据请求序号和文档内容返回每条文档的状态码
+type fakeES struct {
+	mu       sync.Mutex
+	requests [][]string // 每个 bulk 请求中各文档的 content
+	bodySize []int
+	respond  func(request int, contents []string) []int
+}
+
+func (f *fakeES) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("X-Elastic-Product", "Elasticsearch")
+	w.Header().Set("Content-Type", "application/json")
+	if r.URL.Path != "/_bulk" {
+		fmt.Fprint(w, `{"version":{"number":"8.11.0"}}`)
+		return
+	}
+
+	var contents []string
+	size := 0
+	scanner := bufio.NewScanner(r.Body)
+	scanner.Buffer(make([]byte, 1<<20), 1<<20)
+	for line := 0; scanner.Scan(); line++ {
+		size += len(scanner.Bytes()) + 1
+		if line%2 == 0 {
+			continue // action 行
+		}
+		var doc LogEntry
+		if err := json.Unmarshal(scanner.Bytes(), &doc); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		contents = append(contents, doc.Content)
+	}
+
+	f.mu.Lock()
+	request := len(f.requests)
+	f.requests = append(f.requests, contents)
+	f.bodySize = append(f.bodySize, size)
+	f.mu.Unlock()
+
+	statuses := make([]int, len(contents))
+	for i := range statuses {
+		statuses[i] = http.StatusCreated
+	}
+	if f.respond != nil {
+		statuses = f.respond(request, contents)
+	}
+
+	errors := false
+	items := make([]map[string]interface{}, len(statuses))
+	for i, status := range statuses {
+		result := map[string]interface{}{"_index": "logs", "status": status}
+		switch {
+		case status == http.StatusTooManyRequests:
+			errors = true
+			result["error"] = map[string]string{"type": "es_rejected_execution_exception", "reason": "queue is full"}
+		case status >= 300:
+			errors = true
+			result["error"] = map[string]string{"type": "mapper_parsing_exception", "reason": "failed to parse field [age]"}
+		}
+		items[i] = map[string]interface{}{"index": result}
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{"took": 1, "errors": errors, "items": items})
+}
+
+// newTestESWriter 创建写入 fakeES 的 ElasticsearchWriter
+func newTestESWriter(t *testing.T, es *fakeES, configure func(config *Config)) *ElasticsearchWriter {
+	t.Helper()
+	server := httptest.NewServer(es)
+	t.Cleanup(server.Close)
+
+	config := DefaultConfig()
+	config.Addresses = []string{server.URL}
+	config.FlushInterval = time.Hour
+	config.DisableCaller = true
+	config.Retry = &RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond}
+	config.OnError = func(err error, entries int) {}
+	if configure != nil {
+		configure(config)
+	}
+	w, err := NewElasticsearchWriter(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return w
+}
+
+func TestElasticsearchWriterRetriesOnlyRetryableItems(t *testing.T) {
+	es := &fakeES{respond: func(request int, contents []string) []int {
+		statuses := make([]int, len(contents))
+		for i, content := range contents {
+			switch {
+			case content == "bad":
+				statuses[i] = http.StatusBadRequest
+			case content == "busy" && request == 0:
+				statuses[i] = http.StatusTooManyRequests
+			default:
+				statuses[i] = http.StatusCreated
+			}
+		}
+		return statuses
+	}}
+	var letters []DeadLetter
+	w := newTestESWriter(t, es, func(config *Config) {
+		config.DeadLetter = DeadLetterFunc(func(letter DeadLetter) error {
+			letters = append(letters, letter)
+			return nil
+		})
+	})
+
+	w.Info("ok")
+	w.Info("bad")
+	w.Info("busy")
+	if err := w.Flush(context.Background()); err == nil || !strings.Contains(err.Error(), "mapper_parsing_exception") {
+		t.Fatalf("Flush() error = %v, want the mapper_parsing_exception reason", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(es.requests) != 2 {
+		t.Fatalf("bulk requests = %d, want 2", len(es.requests))
+	}
+	if got := es.requests[1]; len(got) != 1 || got[0] != "busy" {
+		t.Fatalf("retried documents = %v, want only the 429 item", got)
+	}
+	if len(letters) != 1 || letters[0].Entry.Content != "bad" {
+		t.Fatalf("dead letters = %+v, want only the 400 item", letters)
+	}
+	if !strings.Contains(letters[0].Error, "failed to parse field [age]") {
+		t.Fatalf("dead letter error = %q, want the item's reason", letters[0].Error)
+	}
+	stats := w.Stats()
+	if stats.Flushed != 2 || stats.Failed != 1 || stats.Retried != 1 {
+		t.Fatalf("Flushed = %d, Failed = %d, Retried = %d, want 2, 1 and 1", stats.Flushed, stats.Failed, stats.Retried)
+	}
+}
+
+func TestElasticsearchWriterSuccessfulBulk(t *testing.T) {
+	es := &fakeES{}
+	w := newTestESWriter(t, es, nil)
+
+	for i := 0; i < 5; i++ {
+		w.Info(fmt.Sprintf("message %d", i))
+	}
+	if err := w.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(es.requests) != 1 || len(es.requests[0]) != 5 {
+		t.Fatalf("bulk requests = %v, want one request with 5 documents", es.requests)
+	}
+	if stats := w.Stats(); stats.Flushed != 5 || stats.Failed != 0 || stats.LastError != "" {
+		t.Fatalf("Flushed = %d, Failed = %d, LastError = %q, want 5, 0 and none", stats.Flushed, stats.Failed, stats.LastError)
+	}
+}
+
+func TestElasticsearchWriterSplitsByMaxBulkBytes(t *testing.T) {
+	const maxBulkBytes = 1024
+	es := &fakeES{}
+	w := newTestESWriter(t, es, func(config *Config) {
+		config.MaxBulkBytes = maxBulkBytes
+	})
+
+	for i := 0; i < 20; i++ {
+		w.Info(fmt.Sprintf("message %02d %s", i, strings.Repeat("x", 100)))
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(es.requests) < 2 {
+		t.Fatalf("bulk requests = %d, want the batch split into several requests", len(es.requests))
+	}
+	var sent []string
+	for i, contents := range es.requests {
+		if es.bodySize[i] > maxBulkBytes {
+			t.Fatalf("request %d is %d bytes, exceeds MaxBulkBytes %d", i, es.bodySize[i], maxBulkBytes)
+		}
+		sent = append(sent, contents...)
+	}
+	if len(sent) != 20 {
+		t.Fatalf("documents sent = %d, want 20", len(sent))
+	}
+	for i, content := range sent {
+		if !strings.HasPrefix(content, fmt.Sprintf("message %02d ", i)) {
+			t.Fatalf("document %d = %q, want the original order", i, content)
+		}
+	}
+	if stats := w.Stats(); stats.Flushed != 20 {
+		t.Fatalf("Flushed = %d, want 20", stats.Flushed)
+	}
+}